@@ -0,0 +1,140 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// resolveAny returns the field a value logged with Any or Array stands for,
+// when the value describes itself: a slog.LogValuer is resolved, and an
+// encoding.TextMarshaler, error or fmt.Stringer becomes a string. A
+// json.Marshaler is kept for the JSON formatter, which embeds its output, if
+// rawJSON is set, and becomes a string of its output otherwise. Other values
+// are returned unchanged, to be encoded by their marshalers or by reflection.
+func resolveAny(field Field, rawJSON bool) Field {
+	if field.Type != ObjectType && field.Type != ArrayType {
+		return field
+	}
+	switch field.Interface.(type) {
+	case nil, ObjectMarshaler, ArrayMarshaler:
+		return field
+	}
+
+	if valuer, ok := field.Interface.(slog.LogValuer); ok {
+		resolved := logValueField(field.Key, valuer.LogValue().Resolve())
+		resolved.IsSensitive = field.IsSensitive
+		field = resolved
+		switch field.Interface.(type) {
+		case nil, ObjectMarshaler, ArrayMarshaler, slog.LogValuer:
+			return field
+		}
+		if field.Type != ObjectType && field.Type != ArrayType {
+			return field
+		}
+	}
+
+	if isNilPointer(field.Interface) {
+		return field
+	}
+
+	switch value := field.Interface.(type) {
+	case json.Marshaler:
+		if rawJSON {
+			return field
+		}
+		if text, ok := textOf(value); ok {
+			return anyString(field, text)
+		}
+		return anyString(field, jsonText(value))
+	case encoding.TextMarshaler, error, fmt.Stringer:
+		text, _ := textOf(value)
+		return anyString(field, text)
+	}
+	return field
+}
+
+// textOf returns the text of a value that is an encoding.TextMarshaler, an
+// error or a fmt.Stringer, in that order of preference.
+func textOf(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return err.Error(), true
+		}
+		return string(text), true
+	case error:
+		return v.Error(), true
+	case fmt.Stringer:
+		return stringerValue(v), true
+	}
+	return "", false
+}
+
+// jsonText returns the compacted output of a json.Marshaler, or the error
+// that prevented it.
+func jsonText(m json.Marshaler) string {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return err.Error()
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, data); err != nil {
+		return err.Error()
+	}
+	return compacted.String()
+}
+
+// anyString returns field with its value replaced by a string.
+func anyString(field Field, value string) Field {
+	resolved := Str(field.Key, value)
+	resolved.IsSensitive = field.IsSensitive
+	return resolved
+}
+
+// isNilPointer returns whether value is a nil pointer.
+func isNilPointer(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// logValueField converts a resolved slog.Value to a Field, with groups as
+// Dicts.
+func logValueField(key string, value slog.Value) Field {
+	if value.Kind() != slog.KindGroup {
+		return fieldFromSlogValue(key, value)
+	}
+	group := value.Group()
+	fields := make([]Field, 0, len(group))
+	for _, attr := range group {
+		fields = append(fields, logValueField(attr.Key, attr.Value.Resolve()))
+	}
+	return Dict(key, fields...)
+}
+
+// writeRawJSON writes the output of a json.Marshaler, compacted onto one
+// line. A nil pointer is written as null, and invalid output or an error as
+// a string describing it.
+func writeRawJSON(buf *bytes.Buffer, m json.Marshaler) {
+	if isNilPointer(m) {
+		buf.WriteString("null")
+		return
+	}
+
+	data, err := m.MarshalJSON()
+	if err == nil {
+		tmp := GetBuffer(len(data))
+		defer PutBuffer(tmp)
+		if err = json.Compact(tmp, data); err == nil {
+			buf.Write(tmp.Bytes())
+			return
+		}
+	}
+	buf.WriteByte('"')
+	writeEscapedStringOptimized(buf, err.Error())
+	buf.WriteByte('"')
+}
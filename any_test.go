@@ -0,0 +1,99 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// rawPoint is a json.Marshaler with a pointer receiver.
+type rawPoint struct {
+	out string
+	err error
+}
+
+func (p *rawPoint) MarshalJSON() ([]byte, error) {
+	return []byte(p.out), p.err
+}
+
+// textID is an encoding.TextMarshaler.
+type textID int
+
+func (id textID) MarshalText() ([]byte, error) {
+	return []byte("id-" + string(rune('0'+id))), nil
+}
+
+// valuedUser is a slog.LogValuer.
+type valuedUser struct {
+	name     string
+	password string
+}
+
+func (u valuedUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", u.name))
+}
+
+// named is a fmt.Stringer with a pointer receiver that is safe to call on nil.
+type named struct {
+	name string
+}
+
+func (n *named) String() string {
+	return n.name
+}
+
+// formatAny writes a single Any field with formatter and returns the output.
+func formatAny(t *testing.T, formatter Formatter, key string, value any) string {
+	t.Helper()
+	var buf bytes.Buffer
+	New(NewConfig(WithWriter(&buf), WithFormatter(formatter))).Info("any", Any(key, value))
+	return buf.String()
+}
+
+func TestAnyResolvesSelfDescribingValuesInJSON(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"json marshaler", &rawPoint{out: `{"x": 1, "y": 2}`}, `"v":{"x":1,"y":2}`},
+		{"invalid json", &rawPoint{out: `{"x":`}, `"v":"unexpected end of JSON input"`},
+		{"json error", &rawPoint{err: errors.New("no point")}, `"v":"no point"`},
+		{"nil json marshaler", (*rawPoint)(nil), `"v":null`},
+		{"text marshaler", textID(7), `"v":"id-7"`},
+		{"log valuer", valuedUser{name: "ann", password: "secret"}, `"v":{"name":"ann"}`},
+		{"stringer", &named{name: "db"}, `"v":"db"`},
+		{"nil stringer", (*named)(nil), `"v":"<nil>"`},
+		{"error", errors.New("boom"), `"v":"boom"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out := formatAny(t, NewJSONFormatter(), "v", tt.value)
+			if !json.Valid([]byte(strings.TrimSpace(out))) {
+				t.Fatalf("invalid JSON %s", out)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output = %s, want %s", out, tt.want)
+			}
+			if strings.Contains(out, "secret") {
+				t.Errorf("output leaked a field the LogValuer hides: %s", out)
+			}
+		})
+	}
+}
+
+func TestAnyJSONMarshalerInLogfmt(t *testing.T) {
+	out := formatAny(t, NewLogfmtFormatter(), "v", &rawPoint{out: `{"x": 1}`})
+	if !strings.Contains(out, `v="{\"x\":1}"`) {
+		t.Errorf("output = %s", out)
+	}
+}
+
+func TestAnyLogValuerInLogfmt(t *testing.T) {
+	out := formatAny(t, NewLogfmtFormatter(), "v", valuedUser{name: "ann", password: "secret"})
+	if !strings.Contains(out, `v.name="ann"`) || strings.Contains(out, "secret") {
+		t.Errorf("output = %s", out)
+	}
+}
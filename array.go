@@ -0,0 +1,155 @@
+package onelog
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Typed slices logged as arrays by every formatter.
+type (
+	stringArray   []string
+	intArray      []int
+	int64Array    []int64
+	uintArray     []uint
+	float64Array  []float64
+	boolArray     []bool
+	durationArray []time.Duration
+	timeArray     []time.Time
+	errorArray    []error
+)
+
+// Strs creates a Field with a []string value.
+func Strs(key string, vals []string) Field {
+	return Array(key, stringArray(vals))
+}
+
+// Ints creates a Field with an []int value.
+func Ints(key string, vals []int) Field {
+	return Array(key, intArray(vals))
+}
+
+// Int64s creates a Field with an []int64 value.
+func Int64s(key string, vals []int64) Field {
+	return Array(key, int64Array(vals))
+}
+
+// Uints creates a Field with a []uint value.
+func Uints(key string, vals []uint) Field {
+	return Array(key, uintArray(vals))
+}
+
+// Floats creates a Field with a []float64 value.
+func Floats(key string, vals []float64) Field {
+	return Array(key, float64Array(vals))
+}
+
+// Bools creates a Field with a []bool value.
+func Bools(key string, vals []bool) Field {
+	return Array(key, boolArray(vals))
+}
+
+// Durations creates a Field with a []time.Duration value.
+func Durations(key string, vals []time.Duration) Field {
+	return Array(key, durationArray(vals))
+}
+
+// Times creates a Field with a []time.Time value.
+func Times(key string, vals []time.Time) Field {
+	return Array(key, timeArray(vals))
+}
+
+// Errs creates a Field with an []error value, logged as the errors'
+// messages. Nil errors are logged as "<nil>".
+func Errs(key string, errs []error) Field {
+	return Array(key, errorArray(errs))
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a stringArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendString(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a intArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendInt(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a int64Array) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendInt64(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a uintArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendUint64(uint64(v))
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a float64Array) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendFloat64(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a boolArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendBool(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a durationArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendDuration(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a timeArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, v := range a {
+		enc.AppendTime(v)
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler.
+func (a errorArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, err := range a {
+		if err == nil {
+			enc.AppendString(nilValue)
+		} else {
+			enc.AppendString(err.Error())
+		}
+	}
+	return nil
+}
+
+// LogValue implements slog.LogValuer, so errors forwarded to a slog.Handler
+// are logged as their messages.
+func (a errorArray) LogValue() slog.Value {
+	messages := make([]string, len(a))
+	for i, err := range a {
+		if err == nil {
+			messages[i] = nilValue
+		} else {
+			messages[i] = err.Error()
+		}
+	}
+	return slog.AnyValue(messages)
+}
@@ -0,0 +1,49 @@
+package onelog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArraysInJSON(t *testing.T) {
+	for _, tt := range []struct {
+		field Field
+		want  string
+	}{
+		{Ints("a", []int{1, 2, 3}), `"a":[1,2,3]`},
+		{Ints("a", []int{}), `"a":[]`},
+		{Ints("a", nil), `"a":[]`},
+		{Strs("a", []string{"x", `y"z`}), `"a":["x","y\"z"]`},
+		{Int64s("a", []int64{-1}), `"a":[-1]`},
+		{Uints("a", []uint{7}), `"a":[7]`},
+		{Floats("a", []float64{1.5}), `"a":[1.5]`},
+		{Bools("a", []bool{true, false}), `"a":[true,false]`},
+		{Durations("a", []time.Duration{time.Second}), `"a":["1s"]`},
+		{Errs("a", []error{errors.New("boom"), nil}), `"a":["boom","<nil>"]`},
+	} {
+		var buf bytes.Buffer
+		New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter()))).Info("arrays", tt.field)
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("output = %s, want %s", buf.String(), tt.want)
+		}
+	}
+}
+
+func TestArraysInLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	New(NewConfig(WithWriter(&buf), WithFormatter(NewLogfmtFormatter()))).Info("arrays",
+		Ints("a", []int{1, 2, 3}),
+		Strs("s", []string{"x"}),
+		Ints("empty", nil),
+	)
+
+	out := buf.String()
+	for _, want := range []string{"a.0=1 a.1=2 a.2=3", `s.0="x"`, "empty=[]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output = %s, want %s", out, want)
+		}
+	}
+}
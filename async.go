@@ -48,12 +48,16 @@ type asyncBuffer struct {
 	dynamicResize bool
 	// The resize threshold.
 	resizeThreshold int
-	// The flush interval.
-	flushInterval time.Duration
+	// The flush interval, as a time.Duration accessed atomically.
+	flushInterval int64
+	// Signals the worker that the flush interval changed.
+	intervalCh chan struct{}
 	// Shard count for reducing contention
 	shardCount int
 	// Shard locks
 	shardLocks []sync.Mutex
+	// Serializes flushes between the worker and explicit drains
+	flushLock sync.Mutex
 }
 
 // newAsyncBuffer creates a new asyncBuffer.
@@ -81,7 +85,8 @@ func newAsyncBuffer(size int, writer io.Writer) *asyncBuffer {
 		backpressureMode: DropMode,
 		dynamicResize:    true,
 		resizeThreshold:  75, // 75% utilization
-		flushInterval:    100 * time.Millisecond,
+		flushInterval:    int64(100 * time.Millisecond),
+		intervalCh:       make(chan struct{}, 1),
 		shardCount:       shardCount,
 		shardLocks:       make([]sync.Mutex, shardCount),
 	}
@@ -289,24 +294,62 @@ func (b *asyncBuffer) close() error {
 func (b *asyncBuffer) worker() {
 	defer b.wg.Done()
 
-	ticker := time.NewTicker(b.flushInterval)
+	ticker := time.NewTicker(b.getFlushInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-b.stopCh:
 			// Drain the buffer before exiting.
-			b.flush()
+			b.drain()
 			return
 		case <-ticker.C:
 			// Flush the buffer periodically.
 			b.flush()
+		case <-b.intervalCh:
+			ticker.Reset(b.getFlushInterval())
 		}
 	}
 }
 
+// drain flushes the buffer until every pending entry has been written.
+func (b *asyncBuffer) drain() {
+	for atomic.LoadInt64(&b.readIndex) < atomic.LoadInt64(&b.writeIndex) {
+		b.flush()
+	}
+}
+
+// pending returns the number of entries waiting to be written.
+func (b *asyncBuffer) pending() int64 {
+	return atomic.LoadInt64(&b.writeIndex) - atomic.LoadInt64(&b.readIndex)
+}
+
+// discard drops every pending entry and returns how many were dropped.
+func (b *asyncBuffer) discard() int64 {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
+	b.resizeLock.RLock()
+	defer b.resizeLock.RUnlock()
+
+	readIndex := atomic.LoadInt64(&b.readIndex)
+	writeIndex := atomic.LoadInt64(&b.writeIndex)
+	for i := readIndex; i < writeIndex; i++ {
+		shardIndex := int(i % int64(b.shardCount))
+		b.shardLocks[shardIndex].Lock()
+		b.buffer[i&b.mask] = nil
+		b.shardLocks[shardIndex].Unlock()
+	}
+	atomic.StoreInt64(&b.readIndex, writeIndex)
+
+	return writeIndex - readIndex
+}
+
 // flush flushes the buffer.
 func (b *asyncBuffer) flush() {
+	b.flushLock.Lock()
+	defer b.flushLock.Unlock()
+
 	b.resizeLock.RLock()
 	defer b.resizeLock.RUnlock()
 
@@ -391,9 +434,22 @@ func (b *asyncBuffer) SetResizeThreshold(threshold int) {
 	b.resizeThreshold = threshold
 }
 
-// SetFlushInterval sets the flush interval.
+// SetFlushInterval sets the flush interval. The worker, which is already
+// running, resets its ticker to it.
 func (b *asyncBuffer) SetFlushInterval(interval time.Duration) {
-	b.flushInterval = interval
+	if interval <= 0 {
+		return
+	}
+	atomic.StoreInt64(&b.flushInterval, int64(interval))
+	select {
+	case b.intervalCh <- struct{}{}:
+	default:
+	}
+}
+
+// getFlushInterval returns the flush interval.
+func (b *asyncBuffer) getFlushInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&b.flushInterval))
 }
 
 // GetUtilization returns the buffer utilization (0-100).
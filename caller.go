@@ -0,0 +1,82 @@
+package onelog
+
+import (
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CallerEncoder renders caller info for a formatter.
+type CallerEncoder func(c *CallerInfo) string
+
+// FullCallerEncoder renders the caller as its full file path and line.
+func FullCallerEncoder(c *CallerInfo) string {
+	return c.File + ":" + strconv.Itoa(c.Line)
+}
+
+// ShortCallerEncoder renders the caller as its package directory, file name
+// and line, for example "db/store.go:42".
+func ShortCallerEncoder(c *CallerInfo) string {
+	return shortPath(c.File) + ":" + strconv.Itoa(c.Line)
+}
+
+// ModuleCallerEncoder renders the caller's file path relative to the root of
+// the main module, for example "internal/db/store.go:42". Callers outside the
+// main module, and in its main package, are rendered as by ShortCallerEncoder.
+func ModuleCallerEncoder(c *CallerInfo) string {
+	module := mainModulePath()
+	pkg := functionPackage(c.Function)
+	if module == "" || !strings.HasPrefix(pkg, module+"/") {
+		return ShortCallerEncoder(c)
+	}
+	dir := strings.TrimPrefix(pkg, module+"/")
+	return dir + "/" + filepath.Base(c.File) + ":" + strconv.Itoa(c.Line)
+}
+
+// FunctionCallerEncoder renders the caller as its function name, qualified
+// by the last element of its package path, for example "db.(*Store).Get".
+func FunctionCallerEncoder(c *CallerInfo) string {
+	if slash := strings.LastIndexByte(c.Function, '/'); slash >= 0 {
+		return c.Function[slash+1:]
+	}
+	return c.Function
+}
+
+// shortPath trims a file path to its last directory and file name.
+func shortPath(file string) string {
+	slash := strings.LastIndexByte(file, '/')
+	if slash < 0 {
+		return file
+	}
+	if dir := strings.LastIndexByte(file[:slash], '/'); dir >= 0 {
+		return file[dir+1:]
+	}
+	return file
+}
+
+// functionPackage returns the import path of a qualified function name.
+func functionPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+var (
+	mainModuleOnce sync.Once
+	mainModule     string
+)
+
+// mainModulePath returns the path of the main module from the build info,
+// or "" if it is not available.
+func mainModulePath() string {
+	mainModuleOnce.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			mainModule = info.Main.Path
+		}
+	})
+	return mainModule
+}
@@ -0,0 +1,51 @@
+package onelog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/abdel-issaoui/onelog"
+)
+
+// Caller lookups skip the frames of package onelog, so these tests live
+// outside it.
+
+// logFromHelper logs on behalf of its caller.
+func logFromHelper(logger *onelog.Logger) {
+	logger.InfoEvent().CallerSkip(1).Msg("from helper")
+}
+
+// callerOf logs with logFn and returns the caller written for the entry.
+func callerOf(t *testing.T, logFn func(*onelog.Logger)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	formatter := onelog.NewJSONFormatter()
+	formatter.Options.CallerEncoder = onelog.FullCallerEncoder
+	logFn(onelog.New(onelog.NewConfig(onelog.WithWriter(&buf), onelog.WithFormatter(formatter), onelog.WithCaller(true))))
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	caller, _ := out["caller"].(string)
+	return caller
+}
+
+func TestCallerIsCallSite(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	got := callerOf(t, func(logger *onelog.Logger) { logger.Info("direct") })
+	if want := file + ":" + strconv.Itoa(line+1); got != want {
+		t.Errorf("caller = %s, want %s", got, want)
+	}
+}
+
+func TestCallerSkipReportsHelperCaller(t *testing.T) {
+	_, file, line, _ := runtime.Caller(0)
+	got := callerOf(t, func(logger *onelog.Logger) { logFromHelper(logger) })
+	if want := file + ":" + strconv.Itoa(line+1); got != want {
+		t.Errorf("caller = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package onelog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCallerEncoders(t *testing.T) {
+	c := &CallerInfo{
+		File:     "/src/app/db/store.go",
+		Line:     42,
+		Function: "example.com/app/db.(*Store).Get",
+	}
+	for name, tt := range map[string]struct {
+		encoder CallerEncoder
+		want    string
+	}{
+		"full":     {FullCallerEncoder, "/src/app/db/store.go:42"},
+		"short":    {ShortCallerEncoder, "db/store.go:42"},
+		"function": {FunctionCallerEncoder, "db.(*Store).Get"},
+		"module":   {ModuleCallerEncoder, "db/store.go:42"},
+	} {
+		if got := tt.encoder(c); got != tt.want {
+			t.Errorf("%s encoder = %q, want %q", name, got, tt.want)
+		}
+	}
+
+	if got := ShortCallerEncoder(&CallerInfo{File: "main.go", Line: 1}); got != "main.go:1" {
+		t.Errorf("short encoder without a directory = %q", got)
+	}
+	if got := FunctionCallerEncoder(&CallerInfo{Function: "main.main"}); got != "main.main" {
+		t.Errorf("function encoder without a package path = %q", got)
+	}
+}
+
+func TestCallerEncoderIsUsedByEachFormatter(t *testing.T) {
+	encoder := func(*CallerInfo) string { return "custom-caller" }
+	for name, formatter := range map[string]optionsFormatter{
+		"json":   NewJSONFormatter(),
+		"logfmt": NewLogfmtFormatter(),
+		"text":   NewTextFormatter(),
+	} {
+		formatter.formatterOptions().CallerEncoder = encoder
+		var buf bytes.Buffer
+		New(NewConfig(WithWriter(&buf), WithFormatter(formatter.(Formatter)), WithCaller(true))).Info("hi")
+
+		if !bytes.Contains(buf.Bytes(), []byte("custom-caller")) {
+			t.Errorf("%s output does not use the caller encoder: %s", name, buf.String())
+		}
+	}
+}
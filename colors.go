@@ -93,12 +93,14 @@ const (
 // Colors for log levels.
 var (
 	// Default colors
-	traceColor = cyan
-	debugColor = blue
-	infoColor  = green
-	warnColor  = yellow
-	errorColor = red
-	fatalColor = brightRed
+	traceColor  = cyan
+	debugColor  = blue
+	infoColor   = green
+	warnColor   = yellow
+	errorColor  = red
+	dpanicColor = brightMagenta
+	panicColor  = brightRed
+	fatalColor  = brightRed
 
 	// Special colors
 	resetColor   = reset
@@ -175,9 +177,16 @@ func getColorForLevel(level Level) string {
 		return warnColor
 	case ErrorLevel:
 		return errorColor
+	case DPanicLevel:
+		return dpanicColor
+	case PanicLevel:
+		return panicColor
 	case FatalLevel:
 		return fatalColor
 	default:
+		if color, ok := levels.Load().colors[level]; ok {
+			return color
+		}
 		return defaultColor
 	}
 }
@@ -198,8 +207,14 @@ func SetLevelColor(level Level, color Color) {
 		warnColor = string(color)
 	case ErrorLevel:
 		errorColor = string(color)
+	case DPanicLevel:
+		dpanicColor = string(color)
+	case PanicLevel:
+		panicColor = string(color)
 	case FatalLevel:
 		fatalColor = string(color)
+	default:
+		setCustomLevelColor(level, color)
 	}
 }
 
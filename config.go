@@ -3,7 +3,9 @@
 package onelog
 
 import (
+	"context"
 	"io"
+	"log/slog"
 	"os"
 	"time"
 )
@@ -22,7 +24,14 @@ type Config struct {
 	EnableCaller bool
 	// CallerSkip is the number of stack frames to skip when getting caller info.
 	CallerSkip int
-	// EnableAsync enables asynchronous logging.
+	// EnableStacktrace enables stack traces on entries at and above
+	// StacktraceLevel.
+	EnableStacktrace bool
+	// StacktraceLevel is the level at and above which entries get a stack trace
+	// when EnableStacktrace is set. Disabled turns stack traces off.
+	StacktraceLevel Level
+	// EnableAsync enables asynchronous logging. It applies to Writer only:
+	// entries sent to a Core are written by the Core synchronously.
 	EnableAsync bool
 	// AsyncBufferSize is the size of the async buffer.
 	AsyncBufferSize int
@@ -34,9 +43,11 @@ type Config struct {
 	Sampler Sampler
 	// Hooks are functions called for each log entry.
 	Hooks []Hook
-	// RedactSensitiveFields enables redaction of sensitive fields.
+	// RedactSensitiveFields enables redaction of the fields whose keys match
+	// SensitiveKeys or AdditionalSensitiveKeys.
 	RedactSensitiveFields bool
-	// AdditionalSensitiveKeys are additional keys to redact.
+	// AdditionalSensitiveKeys are additional key patterns to redact, with the
+	// syntax of SensitiveKeys.
 	AdditionalSensitiveKeys []string
 	// EnableDynamicBufferResizing enables dynamic buffer resizing.
 	EnableDynamicBufferResizing bool
@@ -44,6 +55,19 @@ type Config struct {
 	BufferResizeThreshold int
 	// FlushInterval is the interval for flushing the async buffer.
 	FlushInterval time.Duration
+	// LevelRegistry holds per-name level overrides for named loggers.
+	LevelRegistry *LevelRegistry
+	// SlogHandler, if set, receives log entries instead of Formatter and Writer.
+	SlogHandler slog.Handler
+	// ContextExtractors derive fields from an entry's context when it is written.
+	ContextExtractors []func(context.Context) []Field
+	// Development makes DPanic entries panic after they are written.
+	Development bool
+	// OnFatal is run after a fatal entry is written. Defaults to exiting with status 1.
+	OnFatal FatalAction
+	// Core, if set, receives log entries instead of Formatter and Writer.
+	// The Core writes them synchronously, whatever EnableAsync says.
+	Core Core
 }
 
 // Option is a function that configures a Config.
@@ -84,6 +108,15 @@ func WithCaller(enabled bool) Option {
 	}
 }
 
+// WithStacktraceLevel enables stack traces on entries at and above the given
+// level. Disabled turns stack traces off.
+func WithStacktraceLevel(level Level) Option {
+	return func(c *Config) {
+		c.EnableStacktrace = level != Disabled
+		c.StacktraceLevel = level
+	}
+}
+
 // WithCallerSkip sets the number of stack frames to skip.
 func WithCallerSkip(skip int) Option {
 	return func(c *Config) {
@@ -140,7 +173,8 @@ func WithRedactSensitiveFields(enabled bool) Option {
 	}
 }
 
-// WithAdditionalSensitiveKeys sets additional keys to redact.
+// WithAdditionalSensitiveKeys sets additional key patterns to redact, such
+// as "ssn", "*_pin" or "card_*".
 func WithAdditionalSensitiveKeys(keys ...string) Option {
 	return func(c *Config) {
 		c.AdditionalSensitiveKeys = keys
@@ -168,6 +202,49 @@ func WithFlushInterval(interval time.Duration) Option {
 	}
 }
 
+// WithLevelRegistry sets the registry of per-name level overrides.
+func WithLevelRegistry(registry *LevelRegistry) Option {
+	return func(c *Config) {
+		c.LevelRegistry = registry
+	}
+}
+
+// WithSlogHandler forwards log entries to the given slog.Handler.
+func WithSlogHandler(handler slog.Handler) Option {
+	return func(c *Config) {
+		c.SlogHandler = handler
+	}
+}
+
+// WithContextExtractors sets the functions that derive fields from an entry's context.
+func WithContextExtractors(extractors ...func(context.Context) []Field) Option {
+	return func(c *Config) {
+		c.ContextExtractors = extractors
+	}
+}
+
+// WithDevelopment sets whether DPanic entries panic after they are written.
+func WithDevelopment(development bool) Option {
+	return func(c *Config) {
+		c.Development = development
+	}
+}
+
+// WithOnFatal sets the action run after a fatal entry is written.
+func WithOnFatal(action FatalAction) Option {
+	return func(c *Config) {
+		c.OnFatal = action
+	}
+}
+
+// WithCore sends log entries to the given Core instead of Formatter and
+// Writer. The Core writes them synchronously, whatever WithAsync says.
+func WithCore(core Core) Option {
+	return func(c *Config) {
+		c.Core = core
+	}
+}
+
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
@@ -177,6 +254,8 @@ func DefaultConfig() *Config {
 		ErrorHandler:             nil,
 		EnableCaller:             false,
 		CallerSkip:               0,
+		EnableStacktrace:         false,
+		StacktraceLevel:          Disabled,
 		EnableAsync:              false,
 		AsyncBufferSize:          8192,
 		BackpressureMode:         DropMode,
@@ -232,6 +311,11 @@ func (c *Config) Clone() *Config {
 		copy(clone.Hooks, c.Hooks)
 	}
 	
+	if c.ContextExtractors != nil {
+		clone.ContextExtractors = make([]func(context.Context) []Field, len(c.ContextExtractors))
+		copy(clone.ContextExtractors, c.ContextExtractors)
+	}
+	
 	if c.AdditionalSensitiveKeys != nil {
 		clone.AdditionalSensitiveKeys = make([]string, len(c.AdditionalSensitiveKeys))
 		copy(clone.AdditionalSensitiveKeys, c.AdditionalSensitiveKeys)
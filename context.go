@@ -0,0 +1,27 @@
+package onelog
+
+import (
+	"context"
+)
+
+// loggerContextKey is the context key for the logger stored by ToContext.
+type loggerContextKey struct{}
+
+// ToContext returns a copy of ctx that carries the given logger.
+func ToContext(ctx context.Context, logger *Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by ToContext, or the default
+// logger if there is none.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return DefaultLogger()
+}
@@ -0,0 +1,88 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFromContextFallsBackToDefaultLogger(t *testing.T) {
+	if got := FromContext(context.Background()); got != DefaultLogger() {
+		t.Errorf("FromContext(empty) = %p, want the default logger %p", got, DefaultLogger())
+	}
+	if got := FromContext(nil); got != DefaultLogger() {
+		t.Errorf("FromContext(nil) = %p, want the default logger %p", got, DefaultLogger())
+	}
+}
+
+func TestToContextRoundTrip(t *testing.T) {
+	logger := New(NewConfig(WithWriter(io.Discard))).Named("api")
+	ctx := ToContext(context.Background(), logger)
+
+	if got := FromContext(ctx); got != logger {
+		t.Errorf("FromContext = %p, want the stored logger %p", got, logger)
+	}
+	child := context.WithValue(ctx, struct{}{}, "other")
+	if got := FromContext(child); got != logger {
+		t.Errorf("FromContext(child) = %p, want the stored logger %p", got, logger)
+	}
+}
+
+// requestIDKey is the context key of the request ID used by the extractor tests.
+type requestIDKey struct{}
+
+func TestContextExtractorsRunAtWriteTime(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	extractor := func(ctx context.Context) []Field {
+		calls++
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []Field{Str("request_id", id)}
+		}
+		return nil
+	}
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter()),
+		WithLevel(InfoLevel), WithContextExtractors(extractor)))
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "r-1")
+
+	logger.WithContext(ctx).Debug("disabled")
+	logger.DebugEvent().WithContext(ctx).Msg("disabled")
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("disabled entries ran the extractor %d times and wrote %q", calls, buf.String())
+	}
+
+	entry := logger.WithContext(ctx)
+	if calls != 0 {
+		t.Errorf("extractor ran %d times before the entry was written", calls)
+	}
+	entry.Info("enabled")
+	if calls != 1 || !strings.Contains(buf.String(), `"request_id":"r-1"`) {
+		t.Errorf("extractor ran %d times, output = %s", calls, buf.String())
+	}
+
+	buf.Reset()
+	logger.Info("no context")
+	if calls != 1 || strings.Contains(buf.String(), "request_id") {
+		t.Errorf("entry without a context ran the extractor: %d calls, output = %s", calls, buf.String())
+	}
+}
+
+func TestWithContextExtractorKeepsParentExtractors(t *testing.T) {
+	var buf bytes.Buffer
+	parent := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter()),
+		WithContextExtractors(func(context.Context) []Field { return []Field{Str("a", "1")} })))
+	child := parent.WithContextExtractor(func(context.Context) []Field { return []Field{Str("b", "2")} })
+
+	child.WithContext(context.Background()).Info("child")
+	if !strings.Contains(buf.String(), `"a":"1"`) || !strings.Contains(buf.String(), `"b":"2"`) {
+		t.Errorf("child output = %s", buf.String())
+	}
+
+	buf.Reset()
+	parent.WithContext(context.Background()).Info("parent")
+	if strings.Contains(buf.String(), `"b"`) {
+		t.Errorf("parent ran the child's extractor: %s", buf.String())
+	}
+}
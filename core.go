@@ -0,0 +1,280 @@
+package onelog
+
+import (
+	"errors"
+	"io"
+	"slices"
+)
+
+// Core is a destination for log entries. A Logger configured with a Core
+// hands each entry that passes its level, sampler and hooks to the Core,
+// which decides whether and how to encode and write it.
+type Core interface {
+	// Enabled returns whether the core writes entries at the given level.
+	Enabled(level Level) bool
+	// With returns a core that adds the given fields to every entry.
+	With(fields []Field) Core
+	// Write writes the entry. The entry must not be retained.
+	Write(e *Entry) error
+	// Sync flushes any buffered entries.
+	Sync() error
+}
+
+// LevelEnabler decides whether a level is enabled. Level and *AtomicLevel
+// implement it.
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
+// Enabled returns whether the given level is at or above l.
+func (l Level) Enabled(level Level) bool {
+	return level >= l
+}
+
+// ioCore is a Core that formats entries with a Formatter and writes them to
+// an io.Writer.
+type ioCore struct {
+	formatter Formatter
+	writer    io.Writer
+	// fields are the fields added by With.
+	fields []Field
+	// encoded holds fields pre-encoded by the formatter, or nil if the
+	// formatter does not implement FieldEncoder.
+	encoded []byte
+}
+
+// NewCore returns a Core that formats every entry with the given formatter
+// and writes it to the given writer. Use NewLevelCore to filter it by level.
+func NewCore(formatter Formatter, writer io.Writer) Core {
+	if formatter == nil {
+		formatter = NewTextFormatter()
+	}
+	if writer == nil {
+		writer = io.Discard
+	}
+	return &ioCore{
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+// Enabled implements Core.
+func (c *ioCore) Enabled(Level) bool {
+	return true
+}
+
+// With implements Core.
+func (c *ioCore) With(fields []Field) Core {
+	if len(fields) == 0 {
+		return c
+	}
+	clone := *c
+	clone.fields = make([]Field, 0, len(c.fields)+len(fields))
+	clone.fields = append(clone.fields, c.fields...)
+	clone.fields = append(clone.fields, fields...)
+	clone.encoded = nil
+	if encoder, ok := c.formatter.(FieldEncoder); ok && preEncodable(clone.fields) && !keyPoliciesActive(c.formatter) {
+		buf := GetBuffer(256)
+		encoder.EncodeFields(buf, clone.fields)
+		clone.encoded = append(make([]byte, 0, buf.Len()), buf.Bytes()...)
+		PutBuffer(buf)
+	}
+	return &clone
+}
+
+// Write implements Core. The core's fields are attached for the duration of
+// the call only, so the same entry can be written to several cores.
+func (c *ioCore) Write(e *Entry) error {
+	if len(c.fields) > 0 {
+		fields, encoded := e.fields, e.encodedFields
+		defer func() {
+			e.fields, e.encodedFields = fields, encoded
+		}()
+		switch {
+		case c.encoded != nil && encoded == nil:
+			e.encodedFields = c.encoded
+		case c.encoded != nil:
+			e.encodedFields = append(slices.Clip(encoded), c.encoded...)
+		default:
+			e.fields = make([]Field, 0, len(c.fields)+len(fields))
+			e.fields = append(e.fields, c.fields...)
+			e.fields = append(e.fields, fields...)
+			e.resolveLazyFields()
+		}
+	}
+
+	buf := GetBuffer(256)
+	defer PutBuffer(buf)
+	if err := c.formatter.Format(buf, e); err != nil {
+		return err
+	}
+	_, err := c.writer.Write(buf.Bytes())
+	return err
+}
+
+// Sync implements Core. It syncs the writer if the writer supports it.
+func (c *ioCore) Sync() error {
+	return syncWriter(c.writer)
+}
+
+// levelCore is a Core that only accepts entries at enabled levels.
+type levelCore struct {
+	core    Core
+	enabler LevelEnabler
+}
+
+// NewLevelCore returns a Core that passes entries to core only when their
+// level is enabled by enabler, for example a Level or an *AtomicLevel.
+func NewLevelCore(core Core, enabler LevelEnabler) Core {
+	return &levelCore{
+		core:    core,
+		enabler: enabler,
+	}
+}
+
+// Enabled implements Core.
+func (c *levelCore) Enabled(level Level) bool {
+	return c.enabler.Enabled(level) && c.core.Enabled(level)
+}
+
+// With implements Core.
+func (c *levelCore) With(fields []Field) Core {
+	return &levelCore{
+		core:    c.core.With(fields),
+		enabler: c.enabler,
+	}
+}
+
+// Write implements Core.
+func (c *levelCore) Write(e *Entry) error {
+	if !c.Enabled(e.level) {
+		return nil
+	}
+	return c.core.Write(e)
+}
+
+// Sync implements Core.
+func (c *levelCore) Sync() error {
+	return c.core.Sync()
+}
+
+// sampledCore is a Core that only accepts entries chosen by a Sampler.
+type sampledCore struct {
+	core    Core
+	sampler Sampler
+}
+
+// NewSampledCore returns a Core that passes entries to core only when the
+// sampler samples them.
+func NewSampledCore(core Core, sampler Sampler) Core {
+	return &sampledCore{
+		core:    core,
+		sampler: sampler,
+	}
+}
+
+// Enabled implements Core.
+func (c *sampledCore) Enabled(level Level) bool {
+	return c.core.Enabled(level)
+}
+
+// With implements Core.
+func (c *sampledCore) With(fields []Field) Core {
+	return &sampledCore{
+		core:    c.core.With(fields),
+		sampler: c.sampler,
+	}
+}
+
+// Write implements Core.
+func (c *sampledCore) Write(e *Entry) error {
+	if !c.sampler.Sample(e) {
+		return nil
+	}
+	return c.core.Write(e)
+}
+
+// Sync implements Core.
+func (c *sampledCore) Sync() error {
+	return c.core.Sync()
+}
+
+// teeCore is a Core that duplicates entries to several cores.
+type teeCore []Core
+
+// NewTee returns a Core that writes each entry to every given core that has
+// its level enabled.
+func NewTee(cores ...Core) Core {
+	switch len(cores) {
+	case 0:
+		return NewNopCore()
+	case 1:
+		return cores[0]
+	}
+	return teeCore(slices.Clone(cores))
+}
+
+// Enabled implements Core.
+func (t teeCore) Enabled(level Level) bool {
+	for _, core := range t {
+		if core.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// With implements Core.
+func (t teeCore) With(fields []Field) Core {
+	clone := make(teeCore, len(t))
+	for i, core := range t {
+		clone[i] = core.With(fields)
+	}
+	return clone
+}
+
+// Write implements Core. Every enabled core is written to, even if an
+// earlier one fails; the errors are joined.
+func (t teeCore) Write(e *Entry) error {
+	var errs []error
+	for _, core := range t {
+		if !core.Enabled(e.level) {
+			continue
+		}
+		if err := core.Write(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync implements Core.
+func (t teeCore) Sync() error {
+	var errs []error
+	for _, core := range t {
+		if err := core.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// nopCore is a Core that discards everything.
+type nopCore struct{}
+
+// NewNopCore returns a Core that discards every entry.
+func NewNopCore() Core {
+	return nopCore{}
+}
+
+// Enabled implements Core.
+func (nopCore) Enabled(Level) bool { return false }
+
+// With implements Core.
+func (c nopCore) With([]Field) Core { return c }
+
+// Write implements Core.
+func (nopCore) Write(*Entry) error { return nil }
+
+// Sync implements Core.
+func (nopCore) Sync() error { return nil }
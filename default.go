@@ -33,6 +33,7 @@ func GetLevel() Level {
 // SetFormatter sets the formatter of the default logger.
 func SetFormatter(formatter Formatter) {
 	defaultLogger.formatter = formatter
+	defaultLogger.encodeBoundFields()
 }
 
 // SetWriter sets the writer of the default logger.
@@ -68,6 +69,11 @@ func SetSampler(sampler Sampler) {
 	defaultLogger.sampler = sampler
 }
 
+// Named returns a child of the default logger with the given name.
+func Named(name string) *Logger {
+	return defaultLogger.Named(name)
+}
+
 // With returns a new entry with the given fields from the default logger.
 func With(fields ...Field) *Entry {
 	return defaultLogger.With(fields...)
@@ -88,6 +94,17 @@ func Close() error {
 	return defaultLogger.Close()
 }
 
+// Sync writes out every buffered log entry of the default logger and syncs its writer.
+func Sync() error {
+	return defaultLogger.Sync()
+}
+
+// Shutdown closes the default logger, writing out buffered log entries until
+// ctx is done.
+func Shutdown(ctx context.Context) error {
+	return defaultLogger.Shutdown(ctx)
+}
+
 // NewDevelopmentLogger returns a logger configured for development.
 func NewDevelopmentLogger() *Logger {
 	return New(NewConfig(
@@ -95,6 +112,7 @@ func NewDevelopmentLogger() *Logger {
 		WithFormatter(NewTextFormatter()),
 		WithWriter(os.Stdout),
 		WithCaller(true),
+		WithDevelopment(true),
 		WithErrorHandler(func(err error) {
 			os.Stderr.WriteString("onelog: " + err.Error() + "\n")
 		}),
@@ -125,10 +143,11 @@ func NewHighPerformanceLogger() *Logger {
 		WithLevel(InfoLevel),
 		WithFormatter(&JSONFormatter{
 			Options: FormatterOptions{
-				DisableQuote:  false,
-				DisableEscape: false,
-				PrettyPrint:   false,
-				TimeFormat:    time.RFC3339Nano,
+				DisableQuote:      false,
+				DisableEscape:     false,
+				PrettyPrint:       false,
+				TimeFormat:        time.RFC3339Nano,
+				ErrorDetailsLevel: ErrorLevel,
 			},
 		}),
 		WithWriter(os.Stdout),
@@ -248,11 +267,72 @@ func Error(msg string, fields ...Field) {
 	defaultLogger.Error(msg, fields...)
 }
 
+// DPanic logs a message at the dpanic level with the default logger, which
+// panics in development mode.
+func DPanic(msg string, fields ...Field) {
+	defaultLogger.DPanic(msg, fields...)
+}
+
+// Panic logs a message at the panic level with the default logger and panics.
+func Panic(msg string, fields ...Field) {
+	defaultLogger.Panic(msg, fields...)
+}
+
 // Fatal logs a message at the fatal level with the default logger and calls os.Exit(1).
 func Fatal(msg string, fields ...Field) {
 	defaultLogger.Fatal(msg, fields...)
 }
 
+// Log logs a message at the given level with the default logger.
+func Log(level Level, msg string, fields ...Field) {
+	defaultLogger.Log(level, msg, fields...)
+}
+
+// TraceEvent starts an event at the trace level with the default logger.
+func TraceEvent() *Entry {
+	return defaultLogger.TraceEvent()
+}
+
+// DebugEvent starts an event at the debug level with the default logger.
+func DebugEvent() *Entry {
+	return defaultLogger.DebugEvent()
+}
+
+// InfoEvent starts an event at the info level with the default logger.
+func InfoEvent() *Entry {
+	return defaultLogger.InfoEvent()
+}
+
+// WarnEvent starts an event at the warn level with the default logger.
+func WarnEvent() *Entry {
+	return defaultLogger.WarnEvent()
+}
+
+// ErrorEvent starts an event at the error level with the default logger.
+func ErrorEvent() *Entry {
+	return defaultLogger.ErrorEvent()
+}
+
+// DPanicEvent starts an event at the dpanic level with the default logger.
+func DPanicEvent() *Entry {
+	return defaultLogger.DPanicEvent()
+}
+
+// PanicEvent starts an event at the panic level with the default logger.
+func PanicEvent() *Entry {
+	return defaultLogger.PanicEvent()
+}
+
+// FatalEvent starts an event at the fatal level with the default logger.
+func FatalEvent() *Entry {
+	return defaultLogger.FatalEvent()
+}
+
+// LogEvent starts an event at the given level with the default logger.
+func LogEvent(level Level) *Entry {
+	return defaultLogger.LogEvent(level)
+}
+
 // Tracef logs a formatted message at the trace level with the default logger.
 func Tracef(format string, args ...interface{}) {
 	defaultLogger.Tracef(format, args...)
@@ -278,6 +358,17 @@ func Errorf(format string, args ...interface{}) {
 	defaultLogger.Errorf(format, args...)
 }
 
+// DPanicf logs a formatted message at the dpanic level with the default logger,
+// which panics in development mode.
+func DPanicf(format string, args ...interface{}) {
+	defaultLogger.DPanicf(format, args...)
+}
+
+// Panicf logs a formatted message at the panic level with the default logger and panics.
+func Panicf(format string, args ...interface{}) {
+	defaultLogger.Panicf(format, args...)
+}
+
 // Fatalf logs a formatted message at the fatal level with the default logger and calls os.Exit(1).
 func Fatalf(format string, args ...interface{}) {
 	defaultLogger.Fatalf(format, args...)
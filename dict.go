@@ -0,0 +1,164 @@
+package onelog
+
+import (
+	"encoding/base64"
+	"log/slog"
+	"time"
+)
+
+// dict is a group of fields logged as one nested object.
+type dict []Field
+
+// Dict creates a Field that groups fields under key. The JSON formatter
+// writes it as a nested object, the logfmt formatter as dotted keys such as
+// http.method=GET and the text formatter as http={method=GET status=200}.
+func Dict(key string, fields ...Field) Field {
+	return Field{
+		Key:       key,
+		Type:      ObjectType,
+		Interface: dict(fields),
+	}
+}
+
+// MarshalLogObject implements ObjectMarshaler.
+func (d dict) MarshalLogObject(enc ObjectEncoder) error {
+	opts := encoderOptions(enc)
+	for _, field := range d {
+		if err := addField(enc, field, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogValue implements slog.LogValuer, so groups forwarded to a slog.Handler
+// become slog groups.
+func (d dict) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(d))
+	for i := range d {
+		attrs[i] = slogAttrFromField(d[i])
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// optionsEncoder is implemented by the encoders of the built-in formatters,
+// which expose the options of their formatter.
+type optionsEncoder interface {
+	options() *FormatterOptions
+}
+
+// encoderOptions returns the formatter options of enc, or the default
+// options if it does not expose them.
+func encoderOptions(enc ObjectEncoder) *FormatterOptions {
+	if oe, ok := enc.(optionsEncoder); ok {
+		return oe.options()
+	}
+	opts := DefaultFormatterOptions()
+	return &opts
+}
+
+// addField adds a field to an object as a member.
+func addField(enc ObjectEncoder, field Field, opts *FormatterOptions) error {
+	key := field.Key
+	if opts.FieldNameConverter != nil {
+		key = opts.FieldNameConverter(key)
+	}
+
+	if field.IsSensitive {
+		enc.AddString(key, opts.RedactedValue)
+		return nil
+	}
+	field = resolveAny(field, false)
+
+	switch field.Type {
+	case BoolType:
+		enc.AddBool(key, field.Integer == 1)
+	case IntType, Int64Type:
+		enc.AddInt64(key, field.Integer)
+	case UintType, Uint64Type:
+		enc.AddUint64(key, uint64(field.Integer))
+	case Float32Type, Float64Type:
+		enc.AddFloat64(key, field.Float)
+	case StringType, ErrorType:
+		enc.AddString(key, field.String)
+	case TimeType:
+		if t, ok := field.Interface.(time.Time); ok {
+			enc.AddTime(key, t)
+		} else {
+			enc.AddString(key, nilValue)
+		}
+	case DurationType:
+		if d, ok := field.Interface.(time.Duration); ok {
+			enc.AddDuration(key, d)
+		} else {
+			enc.AddString(key, nilValue)
+		}
+	case BinaryType:
+		data, _ := field.Interface.([]byte)
+		enc.AddString(key, base64.StdEncoding.EncodeToString(data))
+	case StackType:
+		stack, _ := field.Interface.(Stacktrace)
+		return enc.AddArray(key, ArrayMarshalerFunc(func(frames ArrayEncoder) error {
+			for _, frame := range stack {
+				frames.AppendObject(ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+					enc.AddString("function", frame.Function)
+					enc.AddString("file", frame.File)
+					enc.AddInt("line", frame.Line)
+					return nil
+				}))
+			}
+			return nil
+		}))
+	case ObjectType, ArrayType:
+		if obj, arr := marshalers(field, opts); obj != nil {
+			return enc.AddObject(key, obj)
+		} else if arr != nil {
+			return enc.AddArray(key, arr)
+		}
+		enc.AddString(key, stringifyValue(field.Interface))
+	case StringerType, HexType, IPAddrType, URLType, UUIDType:
+		enc.AddString(key, textValue(field))
+	case ByteSizeType:
+		enc.AddInt64(key, field.Integer)
+	case PercentType:
+		enc.AddFloat64(key, field.Float)
+	default:
+		enc.AddString(key, stringifyValue(field.Interface))
+	}
+	return nil
+}
+
+// Namespace nests the fields added to the entry after it under key, as if
+// they had been grouped with Dict. Namespaces can be nested.
+func (e *Entry) Namespace(key string) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Field{Key: key, Type: NamespaceType})
+	return e
+}
+
+// foldNamespaces replaces each namespace marker of the entry, and the
+// fields after it, with a Dict.
+func (e *Entry) foldNamespaces() {
+	for i := range e.fields {
+		if e.fields[i].Type == NamespaceType {
+			e.fields = append(e.fields[:i], foldNamespace(e.fields[i:]))
+			return
+		}
+	}
+}
+
+// foldNamespace folds the fields following the namespace marker fields[0]
+// into a Dict, folding the namespaces among them first.
+func foldNamespace(fields []Field) Field {
+	members := make([]Field, 0, len(fields)-1)
+	for i := 1; i < len(fields); i++ {
+		if fields[i].Type == NamespaceType {
+			members = append(members, foldNamespace(fields[i:]))
+			break
+		}
+		members = append(members, fields[i])
+	}
+	return Dict(fields[0].Key, members...)
+}
@@ -0,0 +1,70 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFoldNamespaces(t *testing.T) {
+	e := &Entry{fields: []Field{Str("a", "1")}}
+	e.Namespace("req").Str("method", "GET").Namespace("user").Int("id", 7)
+	e.foldNamespaces()
+
+	if len(e.fields) != 2 || e.fields[1].Key != "req" {
+		t.Fatalf("fields = %+v", e.fields)
+	}
+	req := e.fields[1].Interface.(dict)
+	if len(req) != 2 || req[0].Key != "method" || req[1].Key != "user" {
+		t.Fatalf("req = %+v", req)
+	}
+	if user := req[1].Interface.(dict); len(user) != 1 || user[0].Key != "id" || user[0].Integer != 7 {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+func TestNamespaceJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+	logger.InfoEvent().Str("a", "1").Namespace("req").Str("method", "GET").Namespace("empty").Msg("hi")
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	req, _ := out["req"].(map[string]any)
+	if out["a"] != "1" || req["method"] != "GET" {
+		t.Errorf("output = %s", buf.String())
+	}
+	if empty, ok := req["empty"].(map[string]any); !ok || len(empty) != 0 {
+		t.Errorf("empty namespace = %v", req["empty"])
+	}
+}
+
+func TestSlogSinkForwardsNoNamespaceMarker(t *testing.T) {
+	var buf bytes.Buffer
+	hook := func(e *Entry) error {
+		e.Namespace("hook").Str("added", "yes")
+		return nil
+	}
+	logger := New(NewConfig(WithSlogHandler(slog.NewJSONHandler(&buf, nil)), WithHooks(hook)))
+	logger.InfoEvent().Namespace("req").Str("method", "GET").Msg("hi")
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	req, _ := out["req"].(map[string]any)
+	hookGroup, _ := out["hook"].(map[string]any)
+	if req["method"] != "GET" || hookGroup["added"] != "yes" {
+		t.Errorf("output = %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "null") {
+		t.Errorf("forwarded a nil attribute: %s", buf.String())
+	}
+	if got := slogAttrFromField(Field{Key: "ns", Type: NamespaceType}); !got.Equal(slog.Attr{}) {
+		t.Errorf("marker became %v", got)
+	}
+}
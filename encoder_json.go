@@ -0,0 +1,213 @@
+package onelog
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// jsonEncoder is the ObjectEncoder and ArrayEncoder of the JSON formatter.
+// It writes the members of an object, or the elements of an array, as JSON.
+type jsonEncoder struct {
+	buf       *bytes.Buffer
+	opts      *FormatterOptions
+	needComma bool
+}
+
+var jsonEncoderPool = sync.Pool{
+	New: func() interface{} {
+		return &jsonEncoder{}
+	},
+}
+
+// writeJSONMarshaled writes a marshaler as a JSON object or array. If it
+// fails, its error message is written as a string instead.
+func writeJSONMarshaled(buf *bytes.Buffer, opts *FormatterOptions, obj ObjectMarshaler, arr ArrayMarshaler) {
+	tmp := GetBuffer(256)
+	defer PutBuffer(tmp)
+
+	var err error
+	if obj != nil {
+		err = encodeJSONObject(tmp, opts, obj)
+	} else {
+		err = encodeJSONArray(tmp, opts, arr)
+	}
+	if err != nil {
+		buf.WriteByte('"')
+		writeEscapedStringOptimized(buf, err.Error())
+		buf.WriteByte('"')
+		return
+	}
+	buf.Write(tmp.Bytes())
+}
+
+// encodeJSONObject writes obj as a JSON object.
+func encodeJSONObject(buf *bytes.Buffer, opts *FormatterOptions, obj ObjectMarshaler) error {
+	enc := jsonEncoderPool.Get().(*jsonEncoder)
+	enc.buf, enc.opts, enc.needComma = buf, opts, false
+
+	buf.WriteByte('{')
+	err := obj.MarshalLogObject(enc)
+	buf.WriteByte('}')
+
+	enc.buf, enc.opts = nil, nil
+	jsonEncoderPool.Put(enc)
+	return err
+}
+
+// encodeJSONArray writes arr as a JSON array.
+func encodeJSONArray(buf *bytes.Buffer, opts *FormatterOptions, arr ArrayMarshaler) error {
+	enc := jsonEncoderPool.Get().(*jsonEncoder)
+	enc.buf, enc.opts, enc.needComma = buf, opts, false
+
+	buf.WriteByte('[')
+	err := arr.MarshalLogArray(enc)
+	buf.WriteByte(']')
+
+	enc.buf, enc.opts = nil, nil
+	jsonEncoderPool.Put(enc)
+	return err
+}
+
+// key writes the separator and key of an object member.
+func (enc *jsonEncoder) key(key string) {
+	enc.separate()
+	enc.buf.WriteByte('"')
+	writeEscapedStringOptimized(enc.buf, key)
+	enc.buf.WriteString("\":")
+}
+
+// separate writes a comma before every member or element but the first.
+func (enc *jsonEncoder) separate() {
+	if enc.needComma {
+		enc.buf.WriteByte(',')
+	}
+	enc.needComma = true
+}
+
+// string writes a string, truncated as configured.
+func (enc *jsonEncoder) string(value string) {
+	enc.buf.WriteByte('"')
+	if enc.opts.TruncateStrings > 0 && len(value) > enc.opts.TruncateStrings {
+		writeEscapedStringOptimized(enc.buf, value[:enc.opts.TruncateStrings])
+		enc.buf.WriteString("...")
+	} else {
+		writeEscapedStringOptimized(enc.buf, value)
+	}
+	enc.buf.WriteByte('"')
+}
+
+// bool writes a boolean.
+func (enc *jsonEncoder) bool(value bool) {
+	enc.buf.Write(strconv.AppendBool(enc.buf.AvailableBuffer(), value))
+}
+
+// int64 writes a signed integer.
+func (enc *jsonEncoder) int64(value int64) {
+	enc.buf.Write(strconv.AppendInt(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// uint64 writes an unsigned integer.
+func (enc *jsonEncoder) uint64(value uint64) {
+	enc.buf.Write(strconv.AppendUint(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// float64 writes a float.
+func (enc *jsonEncoder) float64(value float64) {
+	enc.buf.Write(appendJSONFloat(enc.buf.AvailableBuffer(), value))
+}
+
+// time writes a time in the configured format.
+func (enc *jsonEncoder) time(value time.Time) {
+	enc.buf.WriteByte('"')
+	enc.buf.Write(value.AppendFormat(enc.buf.AvailableBuffer(), enc.opts.TimeFormat))
+	enc.buf.WriteByte('"')
+}
+
+// duration writes a duration.
+func (enc *jsonEncoder) duration(value time.Duration) {
+	enc.buf.WriteByte('"')
+	enc.buf.WriteString(value.String())
+	enc.buf.WriteByte('"')
+}
+
+// options implements optionsEncoder.
+func (enc *jsonEncoder) options() *FormatterOptions {
+	return enc.opts
+}
+
+// AddString implements ObjectEncoder.
+func (enc *jsonEncoder) AddString(key, value string) { enc.key(key); enc.string(value) }
+
+// AddBool implements ObjectEncoder.
+func (enc *jsonEncoder) AddBool(key string, value bool) { enc.key(key); enc.bool(value) }
+
+// AddInt implements ObjectEncoder.
+func (enc *jsonEncoder) AddInt(key string, value int) { enc.key(key); enc.int64(int64(value)) }
+
+// AddInt64 implements ObjectEncoder.
+func (enc *jsonEncoder) AddInt64(key string, value int64) { enc.key(key); enc.int64(value) }
+
+// AddUint64 implements ObjectEncoder.
+func (enc *jsonEncoder) AddUint64(key string, value uint64) { enc.key(key); enc.uint64(value) }
+
+// AddFloat64 implements ObjectEncoder.
+func (enc *jsonEncoder) AddFloat64(key string, value float64) { enc.key(key); enc.float64(value) }
+
+// AddTime implements ObjectEncoder.
+func (enc *jsonEncoder) AddTime(key string, value time.Time) { enc.key(key); enc.time(value) }
+
+// AddDuration implements ObjectEncoder.
+func (enc *jsonEncoder) AddDuration(key string, value time.Duration) {
+	enc.key(key)
+	enc.duration(value)
+}
+
+// AddObject implements ObjectEncoder.
+func (enc *jsonEncoder) AddObject(key string, value ObjectMarshaler) error {
+	enc.key(key)
+	return encodeJSONObject(enc.buf, enc.opts, value)
+}
+
+// AddArray implements ObjectEncoder.
+func (enc *jsonEncoder) AddArray(key string, value ArrayMarshaler) error {
+	enc.key(key)
+	return encodeJSONArray(enc.buf, enc.opts, value)
+}
+
+// AppendString implements ArrayEncoder.
+func (enc *jsonEncoder) AppendString(value string) { enc.separate(); enc.string(value) }
+
+// AppendBool implements ArrayEncoder.
+func (enc *jsonEncoder) AppendBool(value bool) { enc.separate(); enc.bool(value) }
+
+// AppendInt implements ArrayEncoder.
+func (enc *jsonEncoder) AppendInt(value int) { enc.separate(); enc.int64(int64(value)) }
+
+// AppendInt64 implements ArrayEncoder.
+func (enc *jsonEncoder) AppendInt64(value int64) { enc.separate(); enc.int64(value) }
+
+// AppendUint64 implements ArrayEncoder.
+func (enc *jsonEncoder) AppendUint64(value uint64) { enc.separate(); enc.uint64(value) }
+
+// AppendFloat64 implements ArrayEncoder.
+func (enc *jsonEncoder) AppendFloat64(value float64) { enc.separate(); enc.float64(value) }
+
+// AppendTime implements ArrayEncoder.
+func (enc *jsonEncoder) AppendTime(value time.Time) { enc.separate(); enc.time(value) }
+
+// AppendDuration implements ArrayEncoder.
+func (enc *jsonEncoder) AppendDuration(value time.Duration) { enc.separate(); enc.duration(value) }
+
+// AppendObject implements ArrayEncoder.
+func (enc *jsonEncoder) AppendObject(value ObjectMarshaler) error {
+	enc.separate()
+	return encodeJSONObject(enc.buf, enc.opts, value)
+}
+
+// AppendArray implements ArrayEncoder.
+func (enc *jsonEncoder) AppendArray(value ArrayMarshaler) error {
+	enc.separate()
+	return encodeJSONArray(enc.buf, enc.opts, value)
+}
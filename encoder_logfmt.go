@@ -0,0 +1,343 @@
+package onelog
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// logfmtEncoder is the ObjectEncoder and ArrayEncoder of the logfmt
+// formatter. logfmt has no nesting, so it flattens objects and arrays into
+// pairs with dotted keys: object members as prefix.key and array elements as
+// prefix.N.
+type logfmtEncoder struct {
+	f   *LogfmtFormatter
+	buf *bytes.Buffer
+	// path holds the escaped dotted key of the value being encoded. It is
+	// shared with the encoders of nested values, which extend it past prefix.
+	path *[]byte
+	// prefix is the length of the key of this encoder's value in path.
+	prefix int
+	// start is the buffer length before the first pair, so that pairs after
+	// it are separated by a space.
+	start int
+	// index is the index of the next array element.
+	index int
+	// ownPath backs path for the encoder of a field's value, so that keys
+	// are built without allocating once the encoder has been pooled.
+	ownPath []byte
+}
+
+var logfmtEncoderPool = sync.Pool{
+	New: func() interface{} {
+		return &logfmtEncoder{}
+	},
+}
+
+// writeMarshaled writes a marshaler as the pairs of its members or
+// elements, prefixed with key. If it fails, key is written with its error
+// message as the value instead.
+func (f *LogfmtFormatter) writeMarshaled(buf *bytes.Buffer, key string, obj ObjectMarshaler, arr ArrayMarshaler) {
+	tmp := GetBuffer(256)
+	defer PutBuffer(tmp)
+
+	enc := getLogfmtEncoder(f, tmp)
+	writeEscapedLogfmtString(tmp, key)
+	enc.ownPath = append(enc.ownPath[:0], tmp.Bytes()...)
+	tmp.Reset()
+	enc.path, enc.prefix = &enc.ownPath, len(enc.ownPath)
+	err := enc.encode(obj, arr)
+	putLogfmtEncoder(enc)
+
+	if err != nil {
+		writeEscapedLogfmtString(buf, key)
+		buf.WriteByte('=')
+		f.writeQuoted(buf, err.Error())
+		return
+	}
+	buf.Write(tmp.Bytes())
+}
+
+// encode writes the members of obj as prefix.key pairs, or prefix={} if it
+// has none, or the elements of arr as prefix.N pairs, or prefix=[] if it has
+// none.
+func (enc *logfmtEncoder) encode(obj ObjectMarshaler, arr ArrayMarshaler) error {
+	before := enc.buf.Len()
+	var err error
+	empty := "{}"
+	if obj != nil {
+		err = obj.MarshalLogObject(enc)
+	} else {
+		err = arr.MarshalLogArray(enc)
+		empty = "[]"
+	}
+	if err == nil && enc.buf.Len() == before {
+		enc.pathPair()
+		enc.buf.WriteString(empty)
+	}
+	return err
+}
+
+// encodeNested writes a nested object or array, whose key is the first n
+// bytes of path.
+func (enc *logfmtEncoder) encodeNested(n int, obj ObjectMarshaler, arr ArrayMarshaler) error {
+	nested := getLogfmtEncoder(enc.f, enc.buf)
+	nested.path, nested.prefix, nested.start = enc.path, n, enc.start
+	err := nested.encode(obj, arr)
+	putLogfmtEncoder(nested)
+	return err
+}
+
+// getLogfmtEncoder returns a pooled encoder writing pairs to buf.
+func getLogfmtEncoder(f *LogfmtFormatter, buf *bytes.Buffer) *logfmtEncoder {
+	enc := logfmtEncoderPool.Get().(*logfmtEncoder)
+	enc.f, enc.buf, enc.path, enc.prefix, enc.start, enc.index = f, buf, nil, 0, 0, 0
+	return enc
+}
+
+// putLogfmtEncoder returns an encoder to the pool.
+func putLogfmtEncoder(enc *logfmtEncoder) {
+	enc.f, enc.buf, enc.path = nil, nil, nil
+	logfmtEncoderPool.Put(enc)
+}
+
+// writeQuoted writes an escaped string value, quoted unless disabled.
+func (f *LogfmtFormatter) writeQuoted(buf *bytes.Buffer, value string) {
+	if !f.DisableQuoting {
+		buf.WriteByte('"')
+	}
+	writeEscapedLogfmtString(buf, value)
+	if !f.DisableQuoting {
+		buf.WriteByte('"')
+	}
+}
+
+// separate writes the space before a pair, unless it is the first.
+func (enc *logfmtEncoder) separate() {
+	if enc.buf.Len() > enc.start {
+		enc.buf.WriteByte(' ')
+	}
+}
+
+// pathPair writes the separator and the key of the encoder's value.
+func (enc *logfmtEncoder) pathPair() {
+	enc.separate()
+	enc.buf.Write((*enc.path)[:enc.prefix])
+	enc.buf.WriteByte('=')
+}
+
+// memberPair writes the separator and the key of an object member.
+func (enc *logfmtEncoder) memberPair(key string) {
+	enc.separate()
+	enc.buf.Write((*enc.path)[:enc.prefix])
+	enc.buf.WriteByte('.')
+	writeEscapedLogfmtString(enc.buf, key)
+	enc.buf.WriteByte('=')
+}
+
+// elementPair writes the separator and the key of the next array element.
+func (enc *logfmtEncoder) elementPair() {
+	enc.separate()
+	enc.buf.Write((*enc.path)[:enc.prefix])
+	enc.buf.WriteByte('.')
+	enc.buf.Write(strconv.AppendInt(enc.buf.AvailableBuffer(), int64(enc.index), 10))
+	enc.buf.WriteByte('=')
+	enc.index++
+}
+
+// memberPath extends path with the key of an object member, returning the
+// length of the member's key.
+func (enc *logfmtEncoder) memberPath(key string) int {
+	mark := enc.buf.Len()
+	writeEscapedLogfmtString(enc.buf, key)
+	path := append((*enc.path)[:enc.prefix], '.')
+	path = append(path, enc.buf.Bytes()[mark:]...)
+	enc.buf.Truncate(mark)
+	*enc.path = path
+	return len(path)
+}
+
+// elementPath extends path with the index of the next array element,
+// returning the length of the element's key.
+func (enc *logfmtEncoder) elementPath() int {
+	path := append((*enc.path)[:enc.prefix], '.')
+	path = strconv.AppendInt(path, int64(enc.index), 10)
+	enc.index++
+	*enc.path = path
+	return len(path)
+}
+
+// string writes a string value, truncated as configured.
+func (enc *logfmtEncoder) string(value string) {
+	max := enc.f.Options.TruncateStrings
+	if max <= 0 || len(value) <= max {
+		enc.f.writeQuoted(enc.buf, value)
+		return
+	}
+	if !enc.f.DisableQuoting {
+		enc.buf.WriteByte('"')
+	}
+	writeEscapedLogfmtString(enc.buf, value[:max])
+	enc.buf.WriteString("...")
+	if !enc.f.DisableQuoting {
+		enc.buf.WriteByte('"')
+	}
+}
+
+// bool writes a boolean value.
+func (enc *logfmtEncoder) bool(value bool) {
+	enc.buf.Write(strconv.AppendBool(enc.buf.AvailableBuffer(), value))
+}
+
+// int64 writes a signed integer value.
+func (enc *logfmtEncoder) int64(value int64) {
+	enc.buf.Write(strconv.AppendInt(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// uint64 writes an unsigned integer value.
+func (enc *logfmtEncoder) uint64(value uint64) {
+	enc.buf.Write(strconv.AppendUint(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// float64 writes a float value.
+func (enc *logfmtEncoder) float64(value float64) {
+	enc.buf.Write(strconv.AppendFloat(enc.buf.AvailableBuffer(), value, 'f', -1, 64))
+}
+
+// time writes a time value in the configured format.
+func (enc *logfmtEncoder) time(value time.Time) {
+	if !enc.f.DisableQuoting {
+		enc.buf.WriteByte('"')
+	}
+	enc.buf.Write(value.AppendFormat(enc.buf.AvailableBuffer(), enc.f.Options.TimeFormat))
+	if !enc.f.DisableQuoting {
+		enc.buf.WriteByte('"')
+	}
+}
+
+// duration writes a duration value.
+func (enc *logfmtEncoder) duration(value time.Duration) {
+	enc.f.writeQuoted(enc.buf, value.String())
+}
+
+// options implements optionsEncoder.
+func (enc *logfmtEncoder) options() *FormatterOptions {
+	return &enc.f.Options
+}
+
+// AddString implements ObjectEncoder.
+func (enc *logfmtEncoder) AddString(key, value string) {
+	enc.memberPair(key)
+	enc.string(value)
+}
+
+// AddBool implements ObjectEncoder.
+func (enc *logfmtEncoder) AddBool(key string, value bool) {
+	enc.memberPair(key)
+	enc.bool(value)
+}
+
+// AddInt implements ObjectEncoder.
+func (enc *logfmtEncoder) AddInt(key string, value int) {
+	enc.memberPair(key)
+	enc.int64(int64(value))
+}
+
+// AddInt64 implements ObjectEncoder.
+func (enc *logfmtEncoder) AddInt64(key string, value int64) {
+	enc.memberPair(key)
+	enc.int64(value)
+}
+
+// AddUint64 implements ObjectEncoder.
+func (enc *logfmtEncoder) AddUint64(key string, value uint64) {
+	enc.memberPair(key)
+	enc.uint64(value)
+}
+
+// AddFloat64 implements ObjectEncoder.
+func (enc *logfmtEncoder) AddFloat64(key string, value float64) {
+	enc.memberPair(key)
+	enc.float64(value)
+}
+
+// AddTime implements ObjectEncoder.
+func (enc *logfmtEncoder) AddTime(key string, value time.Time) {
+	enc.memberPair(key)
+	enc.time(value)
+}
+
+// AddDuration implements ObjectEncoder.
+func (enc *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	enc.memberPair(key)
+	enc.duration(value)
+}
+
+// AddObject implements ObjectEncoder.
+func (enc *logfmtEncoder) AddObject(key string, value ObjectMarshaler) error {
+	return enc.encodeNested(enc.memberPath(key), value, nil)
+}
+
+// AddArray implements ObjectEncoder.
+func (enc *logfmtEncoder) AddArray(key string, value ArrayMarshaler) error {
+	return enc.encodeNested(enc.memberPath(key), nil, value)
+}
+
+// AppendString implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendString(value string) {
+	enc.elementPair()
+	enc.string(value)
+}
+
+// AppendBool implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendBool(value bool) {
+	enc.elementPair()
+	enc.bool(value)
+}
+
+// AppendInt implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendInt(value int) {
+	enc.elementPair()
+	enc.int64(int64(value))
+}
+
+// AppendInt64 implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendInt64(value int64) {
+	enc.elementPair()
+	enc.int64(value)
+}
+
+// AppendUint64 implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendUint64(value uint64) {
+	enc.elementPair()
+	enc.uint64(value)
+}
+
+// AppendFloat64 implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendFloat64(value float64) {
+	enc.elementPair()
+	enc.float64(value)
+}
+
+// AppendTime implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendTime(value time.Time) {
+	enc.elementPair()
+	enc.time(value)
+}
+
+// AppendDuration implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendDuration(value time.Duration) {
+	enc.elementPair()
+	enc.duration(value)
+}
+
+// AppendObject implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendObject(value ObjectMarshaler) error {
+	return enc.encodeNested(enc.elementPath(), value, nil)
+}
+
+// AppendArray implements ArrayEncoder.
+func (enc *logfmtEncoder) AppendArray(value ArrayMarshaler) error {
+	return enc.encodeNested(enc.elementPath(), nil, value)
+}
@@ -0,0 +1,87 @@
+package onelog
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testZips []int
+
+func (z *testZips) MarshalLogArray(enc ArrayEncoder) error {
+	for _, zip := range *z {
+		enc.AppendInt(zip)
+	}
+	return nil
+}
+
+type testAddress struct {
+	city string
+	zips testZips
+}
+
+func (a *testAddress) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("city", a.city)
+	return enc.AddArray("zip codes", &a.zips)
+}
+
+type testAddresses []testAddress
+
+func (t *testAddresses) MarshalLogArray(enc ArrayEncoder) error {
+	for i := range *t {
+		if err := enc.AppendObject(&(*t)[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type testUser struct {
+	name    string
+	address testAddress
+	tags    testAddresses
+}
+
+func (u *testUser) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	if err := enc.AddObject("address", &u.address); err != nil {
+		return err
+	}
+	return enc.AddArray("tags", &u.tags)
+}
+
+func TestLogfmtEncoderFlattensKeys(t *testing.T) {
+	f := NewLogfmtFormatter()
+	user := testUser{
+		name:    "ann",
+		address: testAddress{city: "Paris", zips: testZips{75001, 75002}},
+		tags:    testAddresses{{city: "Lyon"}},
+	}
+
+	var buf bytes.Buffer
+	f.writeMarshaled(&buf, "user", &user, nil)
+	want := `user.name="ann" user.address.city="Paris" user.address.zip\ codes.0=75001 ` +
+		`user.address.zip\ codes.1=75002 user.tags.0.city="Lyon" user.tags.0.zip\ codes=[]`
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestLogfmtEncoderDoesNotAllocateKeys(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not meaningful with the race detector")
+	}
+	f := NewLogfmtFormatter()
+	user := testUser{
+		name:    "ann",
+		address: testAddress{city: "Paris", zips: testZips{75001, 75002}},
+		tags:    testAddresses{{city: "Lyon"}},
+	}
+	var buf bytes.Buffer
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		f.writeMarshaled(&buf, "user", &user, nil)
+	})
+	if allocs > 0 {
+		t.Errorf("writeMarshaled allocated %v times per run", allocs)
+	}
+}
@@ -0,0 +1,207 @@
+package onelog
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// textEncoder is the ObjectEncoder and ArrayEncoder of the text formatter.
+// It writes objects as {key=value key=value} and arrays as [value value].
+type textEncoder struct {
+	buf       *bytes.Buffer
+	opts      *FormatterOptions
+	needSpace bool
+}
+
+var textEncoderPool = sync.Pool{
+	New: func() interface{} {
+		return &textEncoder{}
+	},
+}
+
+// writeTextMarshaled writes a marshaler as a text object or array. If it
+// fails, its error message is written instead.
+func writeTextMarshaled(buf *bytes.Buffer, opts *FormatterOptions, obj ObjectMarshaler, arr ArrayMarshaler) {
+	tmp := GetBuffer(256)
+	defer PutBuffer(tmp)
+
+	var err error
+	if obj != nil {
+		err = encodeTextObject(tmp, opts, obj)
+	} else {
+		err = encodeTextArray(tmp, opts, arr)
+	}
+	if err != nil {
+		buf.WriteString(err.Error())
+		return
+	}
+	buf.Write(tmp.Bytes())
+}
+
+// encodeTextObject writes obj as {key=value key=value}.
+func encodeTextObject(buf *bytes.Buffer, opts *FormatterOptions, obj ObjectMarshaler) error {
+	enc := textEncoderPool.Get().(*textEncoder)
+	enc.buf, enc.opts, enc.needSpace = buf, opts, false
+
+	buf.WriteByte('{')
+	err := obj.MarshalLogObject(enc)
+	buf.WriteByte('}')
+
+	enc.buf, enc.opts = nil, nil
+	textEncoderPool.Put(enc)
+	return err
+}
+
+// encodeTextArray writes arr as [value value].
+func encodeTextArray(buf *bytes.Buffer, opts *FormatterOptions, arr ArrayMarshaler) error {
+	enc := textEncoderPool.Get().(*textEncoder)
+	enc.buf, enc.opts, enc.needSpace = buf, opts, false
+
+	buf.WriteByte('[')
+	err := arr.MarshalLogArray(enc)
+	buf.WriteByte(']')
+
+	enc.buf, enc.opts = nil, nil
+	textEncoderPool.Put(enc)
+	return err
+}
+
+// key writes the separator and key of an object member.
+func (enc *textEncoder) key(key string) {
+	enc.separate()
+	enc.buf.WriteString(key)
+	enc.buf.WriteByte('=')
+}
+
+// separate writes a space before every member or element but the first.
+func (enc *textEncoder) separate() {
+	if enc.needSpace {
+		enc.buf.WriteByte(' ')
+	}
+	enc.needSpace = true
+}
+
+// string writes a string, truncated as configured.
+func (enc *textEncoder) string(value string) {
+	if enc.opts.TruncateStrings > 0 && len(value) > enc.opts.TruncateStrings {
+		enc.buf.WriteString(value[:enc.opts.TruncateStrings])
+		enc.buf.WriteString("...")
+	} else {
+		enc.buf.WriteString(value)
+	}
+}
+
+// options implements optionsEncoder.
+func (enc *textEncoder) options() *FormatterOptions {
+	return enc.opts
+}
+
+// AddString implements ObjectEncoder.
+func (enc *textEncoder) AddString(key, value string) { enc.key(key); enc.string(value) }
+
+// AddBool implements ObjectEncoder.
+func (enc *textEncoder) AddBool(key string, value bool) {
+	enc.key(key)
+	enc.buf.Write(strconv.AppendBool(enc.buf.AvailableBuffer(), value))
+}
+
+// AddInt implements ObjectEncoder.
+func (enc *textEncoder) AddInt(key string, value int) { enc.AddInt64(key, int64(value)) }
+
+// AddInt64 implements ObjectEncoder.
+func (enc *textEncoder) AddInt64(key string, value int64) {
+	enc.key(key)
+	enc.buf.Write(strconv.AppendInt(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// AddUint64 implements ObjectEncoder.
+func (enc *textEncoder) AddUint64(key string, value uint64) {
+	enc.key(key)
+	enc.buf.Write(strconv.AppendUint(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// AddFloat64 implements ObjectEncoder.
+func (enc *textEncoder) AddFloat64(key string, value float64) {
+	enc.key(key)
+	enc.buf.Write(strconv.AppendFloat(enc.buf.AvailableBuffer(), value, 'f', -1, 64))
+}
+
+// AddTime implements ObjectEncoder.
+func (enc *textEncoder) AddTime(key string, value time.Time) {
+	enc.key(key)
+	enc.buf.Write(value.AppendFormat(enc.buf.AvailableBuffer(), enc.opts.TimeFormat))
+}
+
+// AddDuration implements ObjectEncoder.
+func (enc *textEncoder) AddDuration(key string, value time.Duration) {
+	enc.key(key)
+	enc.buf.WriteString(value.String())
+}
+
+// AddObject implements ObjectEncoder.
+func (enc *textEncoder) AddObject(key string, value ObjectMarshaler) error {
+	enc.key(key)
+	return encodeTextObject(enc.buf, enc.opts, value)
+}
+
+// AddArray implements ObjectEncoder.
+func (enc *textEncoder) AddArray(key string, value ArrayMarshaler) error {
+	enc.key(key)
+	return encodeTextArray(enc.buf, enc.opts, value)
+}
+
+// AppendString implements ArrayEncoder.
+func (enc *textEncoder) AppendString(value string) { enc.separate(); enc.string(value) }
+
+// AppendBool implements ArrayEncoder.
+func (enc *textEncoder) AppendBool(value bool) {
+	enc.separate()
+	enc.buf.Write(strconv.AppendBool(enc.buf.AvailableBuffer(), value))
+}
+
+// AppendInt implements ArrayEncoder.
+func (enc *textEncoder) AppendInt(value int) { enc.AppendInt64(int64(value)) }
+
+// AppendInt64 implements ArrayEncoder.
+func (enc *textEncoder) AppendInt64(value int64) {
+	enc.separate()
+	enc.buf.Write(strconv.AppendInt(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// AppendUint64 implements ArrayEncoder.
+func (enc *textEncoder) AppendUint64(value uint64) {
+	enc.separate()
+	enc.buf.Write(strconv.AppendUint(enc.buf.AvailableBuffer(), value, 10))
+}
+
+// AppendFloat64 implements ArrayEncoder.
+func (enc *textEncoder) AppendFloat64(value float64) {
+	enc.separate()
+	enc.buf.Write(strconv.AppendFloat(enc.buf.AvailableBuffer(), value, 'f', -1, 64))
+}
+
+// AppendTime implements ArrayEncoder.
+func (enc *textEncoder) AppendTime(value time.Time) {
+	enc.separate()
+	enc.buf.Write(value.AppendFormat(enc.buf.AvailableBuffer(), enc.opts.TimeFormat))
+}
+
+// AppendDuration implements ArrayEncoder.
+func (enc *textEncoder) AppendDuration(value time.Duration) {
+	enc.separate()
+	enc.buf.WriteString(value.String())
+}
+
+// AppendObject implements ArrayEncoder.
+func (enc *textEncoder) AppendObject(value ObjectMarshaler) error {
+	enc.separate()
+	return encodeTextObject(enc.buf, enc.opts, value)
+}
+
+// AppendArray implements ArrayEncoder.
+func (enc *textEncoder) AppendArray(value ArrayMarshaler) error {
+	enc.separate()
+	return encodeTextArray(enc.buf, enc.opts, value)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"time"
 )
 
-// Entry represents a log entry with fields.
+// Entry represents a log entry with fields. A nil *Entry, as returned by
+// the event methods of a Logger for a disabled level, is valid: all its
+// methods do nothing.
 type Entry struct {
 	logger     *Logger
 	level      Level
@@ -17,6 +20,20 @@ type Entry struct {
 	fieldPool  *fieldPool
 	ctx        context.Context
 	callerInfo *CallerInfo
+	// name is the dotted name of the logger.
+	name string
+	// encodedFields holds the logger's bound fields as pre-encoded by the formatter.
+	encodedFields []byte
+	// span holds the trace correlation data from the entry's context.
+	span spanInfo
+	// callerSkip is the number of frames skipped for this entry on top of
+	// the logger's caller skip.
+	callerSkip int
+	// noCaller leaves callerInfo empty for an entry whose call site is
+	// unknown, instead of looking it up on the stack.
+	noCaller bool
+	// lazies holds the lazy fields already built for this entry.
+	lazies []resolvedLazy
 }
 
 // CallerInfo contains information about the caller of the log function.
@@ -24,113 +41,183 @@ type CallerInfo struct {
 	File     string
 	Line     int
 	Function string
+	// pc is the program counter of the call site, if known.
+	pc uintptr
 }
 
 // newEntry creates a new Entry.
 func (l *Logger) newEntry() *Entry {
 	e := entryPool.Get().(*Entry)
 	e.logger = l
+	e.level = InfoLevel
 	e.time = time.Now()
 	e.fields = e.fields[:0] // Reset fields slice
 	e.fieldPool = l.fieldPool
+	e.name = l.name
 	e.ctx = nil
 	e.callerInfo = nil
+	e.encodedFields = nil
+	e.span = spanInfo{}
+	e.callerSkip = 0
+	e.noCaller = false
+	e.lazies = e.lazies[:0]
 	return e
 }
 
 // Enabled returns whether the given level is enabled.
 func (e *Entry) Enabled() bool {
-	return e.level >= e.logger.level.Level()
+	if e == nil {
+		return false
+	}
+	return e.logger.enabled(e.level)
 }
 
 // WithField adds a field to the entry.
 func (e *Entry) WithField(field Field) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, field)
 	return e
 }
 
 // WithFields adds multiple fields to the entry.
 func (e *Entry) WithFields(fields []Field) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, fields...)
 	return e
 }
 
 // WithContext adds a context to the entry.
 func (e *Entry) WithContext(ctx context.Context) *Entry {
+	if e == nil {
+		return e
+	}
 	e.ctx = ctx
 	return e
 }
 
 // Context returns the entry's context or context.Background() if nil.
 func (e *Entry) Context() context.Context {
-	if e.ctx == nil {
+	if e == nil || e.ctx == nil {
 		return context.Background()
 	}
 	return e.ctx
 }
 
+// lookupField returns the first field with the given key, searching the
+// entry's own fields before those bound to the logger.
+func (e *Entry) lookupField(key string) *Field {
+	for i := range e.fields {
+		if e.fields[i].Key == key {
+			return &e.fields[i]
+		}
+	}
+	for i := range e.logger.boundFields {
+		if e.logger.boundFields[i].Key == key {
+			return &e.logger.boundFields[i]
+		}
+	}
+	return nil
+}
+
 // Str adds a string field to the entry.
 func (e *Entry) Str(key, val string) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Str(key, val))
 	return e
 }
 
 // Bool adds a boolean field to the entry.
 func (e *Entry) Bool(key string, val bool) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Bool(key, val))
 	return e
 }
 
 // Int adds an int field to the entry.
 func (e *Entry) Int(key string, val int) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Int(key, val))
 	return e
 }
 
 // Int64 adds an int64 field to the entry.
 func (e *Entry) Int64(key string, val int64) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Int64(key, val))
 	return e
 }
 
 // Uint adds a uint field to the entry.
 func (e *Entry) Uint(key string, val uint) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Uint(key, val))
 	return e
 }
 
 // Uint64 adds a uint64 field to the entry.
 func (e *Entry) Uint64(key string, val uint64) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Uint64(key, val))
 	return e
 }
 
 // Float32 adds a float32 field to the entry.
 func (e *Entry) Float32(key string, val float32) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Float32(key, val))
 	return e
 }
 
 // Float64 adds a float64 field to the entry.
 func (e *Entry) Float64(key string, val float64) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Float64(key, val))
 	return e
 }
 
 // Time adds a time.Time field to the entry.
 func (e *Entry) Time(key string, val time.Time) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Time(key, val))
 	return e
 }
 
 // Duration adds a time.Duration field to the entry.
 func (e *Entry) Duration(key string, val time.Duration) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Duration(key, val))
 	return e
 }
 
 // Err adds an error field to the entry.
 func (e *Entry) Err(err error) *Entry {
+	if e == nil {
+		return e
+	}
 	if err != nil {
 		e.fields = append(e.fields, Err(err))
 	}
@@ -139,6 +226,9 @@ func (e *Entry) Err(err error) *Entry {
 
 // NamedErr adds a named error field to the entry.
 func (e *Entry) NamedErr(key string, err error) *Entry {
+	if e == nil {
+		return e
+	}
 	if err != nil {
 		e.fields = append(e.fields, NamedErr(key, err))
 	}
@@ -147,25 +237,161 @@ func (e *Entry) NamedErr(key string, err error) *Entry {
 
 // Any adds an interface{} field to the entry.
 func (e *Entry) Any(key string, val interface{}) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Any(key, val))
 	return e
 }
 
+// Object adds a field that marshals itself as an object to the entry.
+func (e *Entry) Object(key string, val ObjectMarshaler) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Object(key, val))
+	return e
+}
+
+// Dict adds a group of fields nested under key to the entry.
+func (e *Entry) Dict(key string, fields ...Field) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Dict(key, fields...))
+	return e
+}
+
 // Binary adds a []byte field to the entry.
 func (e *Entry) Binary(key string, val []byte) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Binary(key, val))
 	return e
 }
 
 // Array adds an array field to the entry.
 func (e *Entry) Array(key string, val interface{}) *Entry {
+	if e == nil {
+		return e
+	}
 	e.fields = append(e.fields, Array(key, val))
 	return e
 }
 
+// Strs adds a []string field to the entry.
+func (e *Entry) Strs(key string, vals []string) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Strs(key, vals))
+	return e
+}
+
+// Ints adds an []int field to the entry.
+func (e *Entry) Ints(key string, vals []int) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Ints(key, vals))
+	return e
+}
+
+// Int64s adds an []int64 field to the entry.
+func (e *Entry) Int64s(key string, vals []int64) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Int64s(key, vals))
+	return e
+}
+
+// Uints adds a []uint field to the entry.
+func (e *Entry) Uints(key string, vals []uint) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Uints(key, vals))
+	return e
+}
+
+// Floats adds a []float64 field to the entry.
+func (e *Entry) Floats(key string, vals []float64) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Floats(key, vals))
+	return e
+}
+
+// Bools adds a []bool field to the entry.
+func (e *Entry) Bools(key string, vals []bool) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Bools(key, vals))
+	return e
+}
+
+// Durations adds a []time.Duration field to the entry.
+func (e *Entry) Durations(key string, vals []time.Duration) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Durations(key, vals))
+	return e
+}
+
+// Times adds a []time.Time field to the entry.
+func (e *Entry) Times(key string, vals []time.Time) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Times(key, vals))
+	return e
+}
+
+// Errs adds an []error field to the entry.
+func (e *Entry) Errs(key string, errs []error) *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, Errs(key, errs))
+	return e
+}
+
+// CallerSkip skips n more frames when finding the entry's caller and stack
+// trace, so helpers that log on behalf of their callers can report them.
+func (e *Entry) CallerSkip(n int) *Entry {
+	if e == nil {
+		return e
+	}
+	e.callerSkip += n
+	return e
+}
+
+// Stack adds a stack trace of the current goroutine to the entry, starting
+// at the caller of Stack.
+func (e *Entry) Stack() *Entry {
+	if e == nil {
+		return e
+	}
+	e.fields = append(e.fields, stackField(stacktraceKey, captureStacktrace(e.skip())))
+	return e
+}
+
+// skip returns the number of frames to skip above the onelog call site.
+func (e *Entry) skip() int {
+	return e.logger.callerSkip + e.callerSkip
+}
+
 // Trace logs a message at the trace level.
 func (e *Entry) Trace(msg string) {
-	if !e.logger.level.Enabled(TraceLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(TraceLevel) {
 		e.release()
 		return
 	}
@@ -176,7 +402,10 @@ func (e *Entry) Trace(msg string) {
 
 // Debug logs a message at the debug level.
 func (e *Entry) Debug(msg string) {
-	if !e.logger.level.Enabled(DebugLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(DebugLevel) {
 		e.release()
 		return
 	}
@@ -189,7 +418,10 @@ func (e *Entry) Debug(msg string) {
  
  // Info logs a message at the info level.
  func (e *Entry) Info(msg string) {
-	if !e.logger.level.Enabled(InfoLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(InfoLevel) {
 		e.release()
 		return
 	}
@@ -200,7 +432,10 @@ func (e *Entry) Debug(msg string) {
  
  // Warn logs a message at the warn level.
  func (e *Entry) Warn(msg string) {
-	if !e.logger.level.Enabled(WarnLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(WarnLevel) {
 		e.release()
 		return
 	}
@@ -211,7 +446,10 @@ func (e *Entry) Debug(msg string) {
  
  // Error logs a message at the error level.
  func (e *Entry) Error(msg string) {
-	if !e.logger.level.Enabled(ErrorLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(ErrorLevel) {
 		e.release()
 		return
 	}
@@ -220,21 +458,94 @@ func (e *Entry) Debug(msg string) {
 	e.write()
  }
  
- // Fatal logs a message at the fatal level and calls os.Exit(1).
+ // DPanic logs a message at the dpanic level. If the logger is in development
+ // mode, it is then flushed and panics.
+ func (e *Entry) DPanic(msg string) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(DPanicLevel) {
+		e.release()
+		return
+	}
+	logger := e.logger
+	e.level = DPanicLevel
+	e.message = msg
+	e.write()
+	if logger.development {
+		logger.flush()
+		panic(msg)
+	}
+ }
+ 
+ // Panic logs a message at the panic level, flushes the logger and panics.
+ func (e *Entry) Panic(msg string) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(PanicLevel) {
+		e.release()
+		return
+	}
+	logger := e.logger
+	e.level = PanicLevel
+	e.message = msg
+	e.write()
+	logger.flush()
+	panic(msg)
+ }
+ 
+ // Fatal logs a message at the fatal level, flushes the logger and runs the
+ // logger's FatalAction, which calls os.Exit(1) by default.
  func (e *Entry) Fatal(msg string) {
-	if !e.logger.level.Enabled(FatalLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(FatalLevel) {
 		e.release()
 		return
 	}
+	logger := e.logger
 	e.level = FatalLevel
 	e.message = msg
 	e.write()
-	exit(1)
+	logger.flush()
+	logger.fatal(msg)
+ }
+ 
+ // Log logs a message at the given level, which may be a custom level
+ // registered with RegisterLevel. The dpanic, panic and fatal levels keep
+ // their panicking and exiting behavior.
+ func (e *Entry) Log(level Level, msg string) {
+	if e == nil {
+		return
+	}
+	switch level {
+	case DPanicLevel:
+		e.DPanic(msg)
+		return
+	case PanicLevel:
+		e.Panic(msg)
+		return
+	case FatalLevel:
+		e.Fatal(msg)
+		return
+	}
+	if level == Disabled || !e.logger.enabled(level) {
+		e.release()
+		return
+	}
+	e.level = level
+	e.message = msg
+	e.write()
  }
  
  // Tracef logs a formatted message at the trace level.
  func (e *Entry) Tracef(format string, args ...interface{}) {
-	if !e.logger.level.Enabled(TraceLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(TraceLevel) {
 		e.release()
 		return
 	}
@@ -245,7 +556,10 @@ func (e *Entry) Debug(msg string) {
  
  // Debugf logs a formatted message at the debug level.
  func (e *Entry) Debugf(format string, args ...interface{}) {
-	if !e.logger.level.Enabled(DebugLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(DebugLevel) {
 		e.release()
 		return
 	}
@@ -256,7 +570,10 @@ func (e *Entry) Debug(msg string) {
  
  // Infof logs a formatted message at the info level.
  func (e *Entry) Infof(format string, args ...interface{}) {
-	if !e.logger.level.Enabled(InfoLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(InfoLevel) {
 		e.release()
 		return
 	}
@@ -267,7 +584,10 @@ func (e *Entry) Debug(msg string) {
  
  // Warnf logs a formatted message at the warn level.
  func (e *Entry) Warnf(format string, args ...interface{}) {
-	if !e.logger.level.Enabled(WarnLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(WarnLevel) {
 		e.release()
 		return
 	}
@@ -278,7 +598,10 @@ func (e *Entry) Debug(msg string) {
  
  // Errorf logs a formatted message at the error level.
  func (e *Entry) Errorf(format string, args ...interface{}) {
-	if !e.logger.level.Enabled(ErrorLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(ErrorLevel) {
 		e.release()
 		return
 	}
@@ -287,29 +610,80 @@ func (e *Entry) Debug(msg string) {
 	e.write()
  }
  
- // Fatalf logs a formatted message at the fatal level and calls os.Exit(1).
+ // DPanicf logs a formatted message at the dpanic level. If the logger is in
+ // development mode, it is then flushed and panics.
+ func (e *Entry) DPanicf(format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(DPanicLevel) {
+		e.release()
+		return
+	}
+	e.DPanic(fmt.Sprintf(format, args...))
+ }
+ 
+ // Panicf logs a formatted message at the panic level, flushes the logger and panics.
+ func (e *Entry) Panicf(format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(PanicLevel) {
+		e.release()
+		return
+	}
+	e.Panic(fmt.Sprintf(format, args...))
+ }
+ 
+ // Fatalf logs a formatted message at the fatal level, flushes the logger and
+ // runs the logger's FatalAction, which calls os.Exit(1) by default.
  func (e *Entry) Fatalf(format string, args ...interface{}) {
-	if !e.logger.level.Enabled(FatalLevel) {
+	if e == nil {
+		return
+	}
+	if !e.logger.enabled(FatalLevel) {
 		e.release()
 		return
 	}
-	e.level = FatalLevel
-	e.message = fmt.Sprintf(format, args...)
-	e.write()
-	exit(1)
+	e.Fatal(fmt.Sprintf(format, args...))
  }
  
  // write writes the entry to the logger's writer.
  func (e *Entry) write() {
+	// Refuse entries once the logger has been shut down
+	if e.logger.closed() {
+		if e.logger.errorHandler != nil {
+			e.logger.errorHandler(ErrLoggerClosed)
+		}
+		e.release()
+		return
+	}
+ 
 	// If sampling is enabled, check if the entry should be sampled.
 	if e.logger.sampler != nil && !e.logger.sampler.Sample(e) {
 		e.release()
 		return
 	}
  
-	// If caller info is enabled, get the caller info.
-	if e.logger.enableCaller {
-		e.callerInfo = getCaller(2)
+	// Nest the fields that follow namespaces
+	e.foldNamespaces()
+ 
+	// If caller info is enabled, get the caller info unless already known.
+	if e.logger.enableCaller && e.callerInfo == nil && !e.noCaller {
+		e.callerInfo = getCaller(e.skip())
+	}
+ 
+	// Capture a stack trace at or above the stack trace level
+	if e.level >= e.logger.stacktraceLevel && !e.hasStack() {
+		e.fields = append(e.fields, stackField(stacktraceKey, captureStacktrace(e.skip())))
+	}
+ 
+	// Add the fields derived from the context
+	if e.ctx != nil {
+		e.captureSpan()
+		for _, extract := range e.logger.contextExtractors {
+			e.fields = append(e.fields, extract(e.ctx)...)
+		}
 	}
  
 	// Apply hooks if any
@@ -321,6 +695,43 @@ func (e *Entry) Debug(msg string) {
 		}
 	}
  
+	// Nest the fields that follow namespaces opened by hooks
+	e.foldNamespaces()
+ 
+	// Build the lazy fields now that the entry is known to be written
+	e.resolveLazyFields()
+ 
+	// Redact the fields whose keys are sensitive
+	if e.logger.redactor != nil {
+		e.redactFields()
+	}
+ 
+	// Forward the entry to the slog handler instead of formatting it
+	if e.logger.slogHandler != nil {
+		e.writeSlog()
+		e.release()
+		return
+	}
+ 
+	// Hand the entry to the core, which carries the bound fields itself
+	if e.logger.core != nil {
+		if err := e.logger.core.Write(e); err != nil && e.logger.errorHandler != nil {
+			e.logger.errorHandler(err)
+		}
+		e.release()
+		return
+	}
+ 
+	// Attach the logger's bound fields, pre-encoded when the formatter supports it
+	if len(e.logger.boundFields) > 0 {
+		if e.logger.boundEncoded != nil {
+			e.encodedFields = e.logger.boundEncoded
+		} else {
+			e.fields = slices.Insert(e.fields, 0, e.logger.boundFields...)
+			e.resolveLazyFields()
+		}
+	}
+ 
 	// Format and write the entry.
 	buf := GetBuffer(256) // Pre-allocate a reasonable size
  
@@ -360,11 +771,20 @@ func (e *Entry) Debug(msg string) {
 	e.message = ""
 	e.ctx = nil
 	e.callerInfo = nil
+	e.encodedFields = nil
+	e.span = spanInfo{}
+	e.callerSkip = 0
+	e.noCaller = false
+	clear(e.lazies)
+	e.lazies = e.lazies[:0]
 	entryPool.Put(e)
  }
  
  // Writer returns an io.Writer that writes to the entry at the given level.
  func (e *Entry) Writer(level Level) io.Writer {
+	if e == nil {
+		return io.Discard
+	}
 	return &entryWriter{
 		entry: e,
 		level: level,
@@ -0,0 +1,110 @@
+package onelog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxErrorChain is the maximum number of wrapped errors followed by
+// describeError, which stops errors whose Unwrap returns themselves or
+// forms a cycle.
+const maxErrorChain = 64
+
+// errorDetails describes an error beyond its message.
+type errorDetails struct {
+	// typ is the error's concrete Go type.
+	typ string
+	// chain holds the messages of the errors it wraps, outermost first.
+	chain []string
+	// members holds the messages of the errors joined by errors.Join or a
+	// multi-%w fmt.Errorf, if the chain ends in such a group.
+	members []string
+	// stack is the stack trace carried by the error, if any.
+	stack string
+}
+
+// describeError collects the details of err.
+func describeError(err error) errorDetails {
+	details := errorDetails{
+		typ: reflect.TypeOf(err).String(),
+	}
+
+	for depth, current := 0, err; current != nil && depth < maxErrorChain; depth++ {
+		if details.stack == "" {
+			details.stack = errorStack(current)
+		}
+		switch wrapper := current.(type) {
+		case interface{ Unwrap() error }:
+			next := wrapper.Unwrap()
+			if next != nil && reflect.TypeOf(next).Comparable() && next == current {
+				next = nil
+			}
+			current = next
+			if current != nil {
+				details.chain = append(details.chain, current.Error())
+			}
+		case interface{ Unwrap() []error }:
+			for _, member := range wrapper.Unwrap() {
+				if member == nil {
+					continue
+				}
+				details.members = append(details.members, member.Error())
+				if details.stack == "" {
+					details.stack = errorStack(member)
+				}
+			}
+			current = nil
+		default:
+			current = nil
+		}
+	}
+
+	return details
+}
+
+// errorStack returns the stack trace exposed by err through a StackTrace
+// method, or through verbose "%+v" formatting when it holds stack frames, or
+// "" if there is none.
+func errorStack(err error) string {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+		if stack := fmt.Sprintf("%+v", method.Call(nil)[0].Interface()); stack != "" {
+			return stack
+		}
+	}
+
+	if _, ok := err.(fmt.Formatter); ok {
+		if verbose := fmt.Sprintf("%+v", err); hasStackFrames(verbose) {
+			return verbose
+		}
+	}
+
+	return ""
+}
+
+// hasStackFrames returns whether s holds a stack trace: a line with a tab
+// followed by a Go source location such as "\t/app/main.go:42".
+func hasStackFrames(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		location, ok := strings.CutPrefix(line, "\t")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(location, ".go:")
+		if i >= 0 && i+4 < len(location) && location[i+4] >= '0' && location[i+4] <= '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// errorDetailsEnabled returns whether the details of the error field should
+// be written for an entry at the given level.
+func errorDetailsEnabled(field Field, level Level, opts FormatterOptions) bool {
+	if field.Type != ErrorType || field.IsSensitive || level < opts.ErrorDetailsLevel {
+		return false
+	}
+	_, ok := field.Interface.(error)
+	return ok
+}
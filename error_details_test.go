@@ -0,0 +1,88 @@
+package onelog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type selfWrapping struct{}
+
+func (e *selfWrapping) Error() string { return "self" }
+func (e *selfWrapping) Unwrap() error { return e }
+
+type cycleError struct{ next *cycleError }
+
+func (e *cycleError) Error() string { return "cycle" }
+func (e *cycleError) Unwrap() error { return e.next }
+
+type verboseError struct{ verbose string }
+
+func (e verboseError) Error() string { return "failed" }
+func (e verboseError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, e.verbose)
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
+
+func TestDescribeErrorTerminates(t *testing.T) {
+	if details := describeError(&selfWrapping{}); len(details.chain) != 0 {
+		t.Errorf("self-wrapping chain = %q", details.chain)
+	}
+
+	a, b := &cycleError{}, &cycleError{}
+	a.next, b.next = b, a
+	if details := describeError(a); len(details.chain) > maxErrorChain {
+		t.Errorf("cyclic chain has %d entries", len(details.chain))
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	chain := verboseError{verbose: "failed: opening config: no such file"}
+	if stack := errorStack(chain); stack != "" {
+		t.Errorf("errorStack of a verbose message = %q, want none", stack)
+	}
+
+	framed := verboseError{verbose: "failed\nmain.run\n\t/app/main.go:42\nmain.main\n\t/app/main.go:12"}
+	if stack := errorStack(framed); stack != framed.verbose {
+		t.Errorf("errorStack = %q, want %q", stack, framed.verbose)
+	}
+
+	wrapped := fmt.Errorf("load: %w", errors.New("missing"))
+	if details := describeError(wrapped); details.stack != "" || len(details.chain) != 1 {
+		t.Errorf("describeError(%v) = %+v", wrapped, details)
+	}
+}
+
+func TestErrorDetailsZeroValueOptions(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewJSONFormatter()
+	formatter.Options.ErrorDetailsLevel = 0
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter)))
+
+	// The zero value is TraceLevel, so every error is detailed
+	logger.Info("info", Err(errors.New("boom")))
+	if !strings.Contains(buf.String(), `"error.type":"*errors.errorString"`) {
+		t.Errorf("zero ErrorDetailsLevel wrote no details at InfoLevel: %s", buf.String())
+	}
+}
+
+func TestErrorDetailsDefaultLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+
+	logger.Info("info", Err(errors.New("boom")))
+	if strings.Contains(buf.String(), "error.type") {
+		t.Errorf("default options wrote details at InfoLevel: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.Error("error", Err(errors.New("boom")))
+	if !strings.Contains(buf.String(), `"error.type":"*errors.errorString"`) {
+		t.Errorf("default options wrote no details at ErrorLevel: %s", buf.String())
+	}
+}
@@ -23,6 +23,25 @@ var (
 	ErrFieldNotFound = errors.New("onelog: field not found")
 )
 
+// ShutdownError is returned by Logger.Shutdown when the context ends before
+// every buffered entry has been written.
+type ShutdownError struct {
+	// Lost is the number of buffered entries that were discarded.
+	Lost int64
+	// Err is the context's error.
+	Err error
+}
+
+// Error implements error.
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("onelog: shutdown lost %d entries: %v", e.Lost, e.Err)
+}
+
+// Unwrap returns the context's error.
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
 // WrapError wraps an error with a message.
 func WrapError(err error, message string) error {
 	if err == nil {
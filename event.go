@@ -0,0 +1,87 @@
+package onelog
+
+import (
+	"fmt"
+)
+
+// TraceEvent starts an event at the trace level. Fields are added with the
+// Entry methods and the event is written by Msg, Msgf or Send:
+//
+//	logger.TraceEvent().Str("key", "value").Int("n", 1).Msg("done")
+//
+// If the level is disabled, it returns a nil *Entry, whose methods do
+// nothing, so a disabled event costs no allocations.
+func (l *Logger) TraceEvent() *Entry {
+	return l.LogEvent(TraceLevel)
+}
+
+// DebugEvent starts an event at the debug level. See TraceEvent.
+func (l *Logger) DebugEvent() *Entry {
+	return l.LogEvent(DebugLevel)
+}
+
+// InfoEvent starts an event at the info level. See TraceEvent.
+func (l *Logger) InfoEvent() *Entry {
+	return l.LogEvent(InfoLevel)
+}
+
+// WarnEvent starts an event at the warn level. See TraceEvent.
+func (l *Logger) WarnEvent() *Entry {
+	return l.LogEvent(WarnLevel)
+}
+
+// ErrorEvent starts an event at the error level. See TraceEvent.
+func (l *Logger) ErrorEvent() *Entry {
+	return l.LogEvent(ErrorLevel)
+}
+
+// DPanicEvent starts an event at the dpanic level. In development, writing
+// it panics. See TraceEvent.
+func (l *Logger) DPanicEvent() *Entry {
+	return l.LogEvent(DPanicLevel)
+}
+
+// PanicEvent starts an event at the panic level. Writing it flushes the
+// logger and panics. See TraceEvent.
+func (l *Logger) PanicEvent() *Entry {
+	return l.LogEvent(PanicLevel)
+}
+
+// FatalEvent starts an event at the fatal level. Writing it flushes the
+// logger and runs the configured FatalAction. See TraceEvent.
+func (l *Logger) FatalEvent() *Entry {
+	return l.LogEvent(FatalLevel)
+}
+
+// LogEvent starts an event at the given level, which may be a custom level
+// registered with RegisterLevel. See TraceEvent.
+func (l *Logger) LogEvent(level Level) *Entry {
+	if level == Disabled || !l.enabled(level) {
+		return nil
+	}
+	e := l.newEntry()
+	e.level = level
+	return e
+}
+
+// Msg writes the entry with the given message at its level: the level of
+// the event method that started it, or info for entries started by With.
+func (e *Entry) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	e.Log(e.level, msg)
+}
+
+// Msgf writes the entry with a formatted message at its level. See Msg.
+func (e *Entry) Msgf(format string, args ...interface{}) {
+	if e == nil {
+		return
+	}
+	e.Msg(fmt.Sprintf(format, args...))
+}
+
+// Send writes the entry with an empty message at its level. See Msg.
+func (e *Entry) Send() {
+	e.Msg("")
+}
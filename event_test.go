@@ -0,0 +1,84 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNilEventIsNoOp(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithLevel(InfoLevel)))
+
+	e := logger.DebugEvent()
+	if e != nil {
+		t.Fatalf("DebugEvent at InfoLevel = %p, want nil", e)
+	}
+	e.Namespace("ns").
+		WithField(Str("a", "b")).
+		WithFields([]Field{Int("n", 1)}).
+		WithContext(context.Background()).
+		Str("s", "v").
+		Bool("b", true).
+		Int("i", 1).
+		Int64("i64", 1).
+		Uint("u", 1).
+		Uint64("u64", 1).
+		Float32("f32", 1).
+		Float64("f64", 1).
+		Time("t", time.Now()).
+		Duration("d", time.Second).
+		Err(errors.New("boom")).
+		NamedErr("cause", errors.New("boom")).
+		Any("any", struct{}{}).
+		Object("obj", nil).
+		Dict("dict", Str("k", "v")).
+		Binary("bin", []byte{1}).
+		Array("arr", []int{1}).
+		Strs("strs", []string{"a"}).
+		Ints("ints", []int{1}).
+		Int64s("int64s", []int64{1}).
+		Uints("uints", []uint{1}).
+		Floats("floats", []float64{1}).
+		Bools("bools", []bool{true}).
+		Durations("durations", []time.Duration{1}).
+		Times("times", []time.Time{{}}).
+		Errs("errs", []error{nil}).
+		CallerSkip(1).
+		Stack().
+		Msg("dropped")
+	e.Msgf("dropped %d", 1)
+	e.Send()
+	e.Panic("dropped")
+	e.Fatal("dropped")
+
+	if e.Enabled() {
+		t.Errorf("nil event is enabled")
+	}
+	if e.Context() == nil {
+		t.Errorf("nil event has a nil context")
+	}
+	if e.Writer(InfoLevel) != io.Discard {
+		t.Errorf("nil event writer is not io.Discard")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nil event wrote %q", buf.String())
+	}
+}
+
+func TestDisabledEventDoesNotAllocate(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not meaningful with the race detector")
+	}
+	logger := New(NewConfig(WithWriter(io.Discard), WithLevel(InfoLevel)))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.DebugEvent().Str("key", "value").Int("n", 1).Msg("dropped")
+	})
+	if allocs != 0 {
+		t.Errorf("disabled event allocated %v times, want 0", allocs)
+	}
+}
@@ -0,0 +1,110 @@
+package onelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// linesWritten returns the number of lines written to w so far.
+func (w *countingWriter) linesWritten() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lines
+}
+
+// recoverPanic runs fn and returns the value it panicked with, if any.
+func recoverPanic(fn func()) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+	fn()
+	return nil
+}
+
+func TestFatalFlushesBeforeOnFatal(t *testing.T) {
+	w := &countingWriter{}
+	written := -1
+	logger := New(NewConfig(WithWriter(w), WithAsync(true), WithFlushInterval(time.Hour),
+		WithOnFatal(func(msg string) {
+			written = w.linesWritten()
+		})))
+	defer logger.Close()
+
+	for i := 0; i < 5; i++ {
+		logger.Info("queued")
+	}
+	logger.Fatal("down")
+
+	if written != 6 {
+		t.Errorf("OnFatal saw %d entries written, want 6", written)
+	}
+}
+
+func TestFatalDefaultsToExit(t *testing.T) {
+	code := -1
+	defer func(previous func(int)) { exit = previous }(exit)
+	exit = func(c int) { code = c }
+
+	New(NewConfig(WithWriter(&bytes.Buffer{}))).Fatal("down")
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
+
+func TestFatalWithPanicAction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithOnFatal(PanicAction())))
+
+	if got := recoverPanic(func() { logger.Fatal("down") }); got != "down" {
+		t.Errorf("Fatal panicked with %v, want %q", got, "down")
+	}
+	if !strings.Contains(buf.String(), "down") {
+		t.Errorf("fatal entry not written: %s", buf.String())
+	}
+}
+
+func TestPanicFlushesBeforePanicking(t *testing.T) {
+	w := &countingWriter{}
+	logger := New(NewConfig(WithWriter(w), WithAsync(true), WithFlushInterval(time.Hour)))
+	defer logger.Close()
+
+	for i := 0; i < 5; i++ {
+		logger.Info("queued")
+	}
+	if got := recoverPanic(func() { logger.Panic("boom") }); got != "boom" {
+		t.Errorf("Panic panicked with %v, want %q", got, "boom")
+	}
+	if got := w.linesWritten(); got != 6 {
+		t.Errorf("%d entries written when Panic panicked, want 6", got)
+	}
+}
+
+func TestDPanicPanicsOnlyInDevelopment(t *testing.T) {
+	var buf bytes.Buffer
+	if got := recoverPanic(func() { New(NewConfig(WithWriter(&buf))).DPanic("odd") }); got != nil {
+		t.Errorf("DPanic panicked with %v outside development", got)
+	}
+	if !strings.Contains(buf.String(), "odd") {
+		t.Errorf("dpanic entry not written: %s", buf.String())
+	}
+
+	logger := New(NewConfig(WithWriter(&buf), WithDevelopment(true)))
+	if got := recoverPanic(func() { logger.DPanic("odd") }); got != "odd" {
+		t.Errorf("DPanic panicked with %v in development, want %q", got, "odd")
+	}
+}
+
+func TestPanicEventMsgPanics(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+
+	got := recoverPanic(func() { logger.PanicEvent().Str("user", "ann").Msg("boom") })
+	if got != "boom" {
+		t.Errorf("PanicEvent().Msg panicked with %v, want %q", got, "boom")
+	}
+	if !strings.Contains(buf.String(), `"user":"ann"`) {
+		t.Errorf("panic event not written: %s", buf.String())
+	}
+}
@@ -40,6 +40,26 @@ const (
 	ArrayType
 	// BinaryType is a []byte field type.
 	BinaryType
+	// StackType is a Stacktrace field type.
+	StackType
+	// LazyType is a field type whose value is built when the entry is written.
+	LazyType
+	// NamespaceType marks the start of the fields nested by Entry.Namespace.
+	NamespaceType
+	// StringerType is a fmt.Stringer field type, rendered when written.
+	StringerType
+	// HexType is a []byte field type written as hex.
+	HexType
+	// IPAddrType is a netip.Addr field type.
+	IPAddrType
+	// URLType is a *url.URL field type.
+	URLType
+	// UUIDType is a [16]byte UUID field type.
+	UUIDType
+	// ByteSizeType is a size in bytes field type.
+	ByteSizeType
+	// PercentType is a percentage field type.
+	PercentType
 )
 
 // Field represents a structured log field.
@@ -181,7 +201,11 @@ func NamedErr(key string, err error) Field {
 	}
 }
 
-// Any creates a Field with an interface{} value.
+// Any creates a Field with an interface{} value. When the entry is written,
+// ObjectMarshalers and ArrayMarshalers encode themselves, slog.LogValuers are
+// resolved, json.Marshalers are embedded as is by the JSON formatter, text
+// marshalers, errors and Stringers are written as strings, and structs, maps
+// and slices are walked by reflection.
 func Any(key string, val interface{}) Field {
 	return Field{
 		Key:       key,
@@ -12,6 +12,34 @@ type Formatter interface {
 	Format(w io.Writer, e *Entry) error
 }
 
+// FieldEncoder is implemented by formatters that can encode fields ahead of
+// time. Fields bound to a child logger are encoded once with it and the
+// result is reused for every entry the child logger writes.
+type FieldEncoder interface {
+	// EncodeFields encodes the given fields into buf, without any leading
+	// or trailing separator.
+	EncodeFields(buf *bytes.Buffer, fields []Field)
+}
+
+// preEncodable returns whether fields bound to a logger can be encoded once
+// up front instead of with every entry. Error fields cannot, since whether
+// their details are written depends on the entry's level, and neither can
+// lazy fields, which must not be built until an entry is written, even
+// within a Dict.
+func preEncodable(fields []Field) bool {
+	for i := range fields {
+		switch fields[i].Type {
+		case ErrorType, LazyType:
+			return false
+		case ObjectType:
+			if group, ok := fields[i].Interface.(dict); ok && !preEncodable(group) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // FormatterOptions contains options for formatters.
 type FormatterOptions struct {
 	// NoTimestamp disables the timestamp in the log entry.
@@ -46,6 +74,29 @@ type FormatterOptions struct {
 	MessageKey string
 	// CallerKey is the key for the caller info.
 	CallerKey string
+	// CallerEncoder renders the caller info. If nil, the JSON formatter
+	// writes the caller as an object with file, line and function, the
+	// logfmt formatter uses FullCallerEncoder and the text formatter uses
+	// ShortCallerEncoder.
+	CallerEncoder CallerEncoder
+	// NameKey is the key for the logger name.
+	NameKey string
+	// TraceKeys controls the keys for trace correlation fields.
+	TraceKeys TraceKeys
+	// ErrorDetailsLevel is the level at and above which error fields are
+	// followed by their type, wrapped chain, joined errors and stack trace.
+	// Disabled turns error details off. DefaultFormatterOptions sets it to
+	// ErrorLevel; the zero value is TraceLevel, which details every error.
+	ErrorDetailsLevel Level
+	// MaxDepth limits how deep values logged with Any or Array are walked
+	// when they are structs, maps, slices or arrays without a marshaler.
+	// Deeper values are written as "<max depth>". Zero means 10.
+	MaxDepth int
+	// DuplicateKeys controls how fields that share a key are written.
+	DuplicateKeys DuplicateKeyPolicy
+	// ReservedKeyPolicy controls how fields named after TimeKey, LevelKey,
+	// MessageKey, CallerKey or NameKey are written.
+	ReservedKeyPolicy ReservedKeyPolicy
 }
 
 var defaultFormatterOptionsInstance *FormatterOptions
@@ -68,11 +119,15 @@ func DefaultFormatterOptions() FormatterOptions {
 			FieldNameConverter: func(s string) string {
 				return s
 			},
-			OmitEmpty:  false,
-			TimeKey:    "time",
-			LevelKey:   "level",
-			MessageKey: "message",
-			CallerKey:  "caller",
+			OmitEmpty:         false,
+			TimeKey:           "time",
+			LevelKey:          "level",
+			MessageKey:        "message",
+			CallerKey:         "caller",
+			NameKey:           "logger",
+			TraceKeys:         DefaultTraceKeys(),
+			ErrorDetailsLevel: ErrorLevel,
+			MaxDepth:          defaultMaxDepth,
 		}
 	})
 
@@ -97,11 +152,15 @@ func DefaultFormatterOptions() FormatterOptions {
 		FieldNameConverter: func(s string) string {
 			return s
 		},
-		OmitEmpty:  false,
-		TimeKey:    "time",
-		LevelKey:   "level",
-		MessageKey: "message",
-		CallerKey:  "caller",
+		OmitEmpty:         false,
+		TimeKey:           "time",
+		LevelKey:          "level",
+		MessageKey:        "message",
+		CallerKey:         "caller",
+		NameKey:           "logger",
+		TraceKeys:         DefaultTraceKeys(),
+		ErrorDetailsLevel: ErrorLevel,
+		MaxDepth:          defaultMaxDepth,
 	}
 }
 
@@ -112,6 +171,7 @@ func FormatField(buf *bytes.Buffer, f Field, opts FormatterOptions) error {
 		_, err := buf.WriteString(opts.RedactedValue)
 		return err
 	}
+	f = resolveAny(f, false)
 
 	switch f.Type {
 	case BoolType:
@@ -244,7 +304,24 @@ func FormatField(buf *bytes.Buffer, f Field, opts FormatterOptions) error {
 				return err
 			}
 		}
-		if _, err := buf.WriteString(stringifyValue(f.Interface)); err != nil {
+		if obj, arr := marshalers(f, &opts); obj != nil || arr != nil {
+			writeTextMarshaled(buf, &opts, obj, arr)
+		} else if _, err := buf.WriteString(stringifyValue(f.Interface)); err != nil {
+			return err
+		}
+		if !opts.DisableQuote {
+			if err := writeQuote(buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	case StringerType, HexType, IPAddrType, URLType, UUIDType:
+		if !opts.DisableQuote {
+			if err := writeQuote(buf); err != nil {
+				return err
+			}
+		}
+		if _, err := buf.Write(appendTextValue(buf.AvailableBuffer(), f)); err != nil {
 			return err
 		}
 		if !opts.DisableQuote {
@@ -253,6 +330,10 @@ func FormatField(buf *bytes.Buffer, f Field, opts FormatterOptions) error {
 			}
 		}
 		return nil
+	case ByteSizeType:
+		return writeInt64(buf, f.Integer)
+	case PercentType:
+		return writeFloat64(buf, f.Float)
 	default:
 		_, err := buf.WriteString("null")
 		return err
@@ -2,7 +2,9 @@ package onelog
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -81,6 +83,19 @@ func (f *JSONFormatter) Format(w io.Writer, e *Entry) error {
 		needComma = true
 	}
 
+	// Write the logger name
+	if e.name != "" && f.Options.NameKey != "" {
+		if needComma {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\"")
+		writeEscapedStringOptimized(buf, f.Options.NameKey)
+		buf.WriteString("\":\"")
+		writeEscapedStringOptimized(buf, e.name)
+		buf.WriteString("\"")
+		needComma = true
+	}
+
 	// Write the message
 	if e.message != "" {
 		if needComma {
@@ -101,28 +116,51 @@ func (f *JSONFormatter) Format(w io.Writer, e *Entry) error {
 		}
 		buf.WriteString("\"")
 		buf.WriteString(f.Options.CallerKey)
-		buf.WriteString("\":{\"file\":\"")
-		writeEscapedStringOptimized(buf, e.callerInfo.File)
-		buf.WriteString("\",\"line\":")
-		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(e.callerInfo.Line), 10))
-		buf.WriteString(",\"function\":\"")
-		writeEscapedStringOptimized(buf, e.callerInfo.Function)
-		buf.WriteString("\"}")
+		if f.Options.CallerEncoder != nil {
+			buf.WriteString("\":\"")
+			writeEscapedStringOptimized(buf, f.Options.CallerEncoder(e.callerInfo))
+			buf.WriteString("\"")
+		} else {
+			buf.WriteString("\":{\"file\":\"")
+			writeEscapedStringOptimized(buf, e.callerInfo.File)
+			buf.WriteString("\",\"line\":")
+			buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(e.callerInfo.Line), 10))
+			buf.WriteString(",\"function\":\"")
+			writeEscapedStringOptimized(buf, e.callerInfo.Function)
+			buf.WriteString("\"}")
+		}
 		needComma = true
 	}
 
-	// Write the fields
-	for _, field := range e.fields {
+	// Write the trace correlation fields
+	traceFields, n := e.traceFields(f.Options.TraceKeys)
+	for _, field := range traceFields[:n] {
 		if needComma {
 			buf.WriteByte(',')
 		}
-		buf.WriteString("\"")
-		writeEscapedStringOptimized(buf, f.Options.FieldNameConverter(field.Key))
-		buf.WriteString("\":")
+		f.writeTraceField(buf, field)
+		needComma = true
+	}
 
-		// Format the field value
-		formatJSONFieldValue(buf, field, f.Options)
-		
+	// Write the pre-encoded fields bound to the logger
+	if len(e.encodedFields) > 0 {
+		if needComma {
+			buf.WriteByte(',')
+		}
+		buf.Write(e.encodedFields)
+		needComma = true
+	}
+
+	// Write the fields
+	fields := applyKeyPolicies(e.fields, e.level, &f.Options)
+	for _, field := range fields {
+		if needComma {
+			buf.WriteByte(',')
+		}
+		f.writeField(buf, field)
+		if errorDetailsEnabled(field, e.level, f.Options) {
+			f.writeErrorDetails(buf, field)
+		}
 		needComma = true
 	}
 
@@ -139,6 +177,95 @@ func (f *JSONFormatter) Format(w io.Writer, e *Entry) error {
 	return err
 }
 
+// EncodeFields implements FieldEncoder.
+func (f *JSONFormatter) EncodeFields(buf *bytes.Buffer, fields []Field) {
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		f.writeField(buf, field)
+	}
+}
+
+// writeField writes a single "key":value pair.
+func (f *JSONFormatter) writeField(buf *bytes.Buffer, field Field) {
+	buf.WriteString("\"")
+	writeEscapedStringOptimized(buf, f.Options.FieldNameConverter(field.Key))
+	buf.WriteString("\":")
+
+	// Format the field value
+	if !field.IsSensitive {
+		field = resolveAny(field, true)
+		if obj, arr := marshalers(field, &f.Options); obj != nil || arr != nil {
+			writeJSONMarshaled(buf, &f.Options, obj, arr)
+			return
+		}
+		if m, ok := field.Interface.(json.Marshaler); ok && (field.Type == ObjectType || field.Type == ArrayType) {
+			writeRawJSON(buf, m)
+			return
+		}
+	}
+	formatJSONFieldValue(buf, field, f.Options)
+}
+
+// writeTraceField writes a trace correlation field. Its key is written as
+// set in TraceKeys, without the FieldNameConverter.
+func (f *JSONFormatter) writeTraceField(buf *bytes.Buffer, field Field) {
+	buf.WriteString("\"")
+	writeEscapedStringOptimized(buf, field.Key)
+	buf.WriteString("\":")
+	formatJSONFieldValue(buf, field, f.Options)
+}
+
+// writeErrorDetails writes the details of an error field as the sibling
+// fields "key.type", "key.chain", "key.errors" and "key.stack".
+func (f *JSONFormatter) writeErrorDetails(buf *bytes.Buffer, field Field) {
+	details := describeError(field.Interface.(error))
+	key := f.Options.FieldNameConverter(field.Key)
+
+	f.writeDetailKey(buf, key, ".type")
+	buf.WriteByte('"')
+	writeEscapedStringOptimized(buf, details.typ)
+	buf.WriteByte('"')
+
+	if len(details.chain) > 0 {
+		f.writeDetailKey(buf, key, ".chain")
+		writeJSONStrings(buf, details.chain)
+	}
+	if len(details.members) > 0 {
+		f.writeDetailKey(buf, key, ".errors")
+		writeJSONStrings(buf, details.members)
+	}
+	if details.stack != "" {
+		f.writeDetailKey(buf, key, ".stack")
+		buf.WriteByte('"')
+		writeEscapedStringOptimized(buf, details.stack)
+		buf.WriteByte('"')
+	}
+}
+
+// writeDetailKey writes a comma and the key of a detail field.
+func (f *JSONFormatter) writeDetailKey(buf *bytes.Buffer, key, suffix string) {
+	buf.WriteString(",\"")
+	writeEscapedStringOptimized(buf, key)
+	buf.WriteString(suffix)
+	buf.WriteString("\":")
+}
+
+// writeJSONStrings writes a JSON array of strings.
+func writeJSONStrings(buf *bytes.Buffer, values []string) {
+	buf.WriteByte('[')
+	for i, value := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('"')
+		writeEscapedStringOptimized(buf, value)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte(']')
+}
+
 // formatJSONFieldValue formats a field value as JSON.
 func formatJSONFieldValue(buf *bytes.Buffer, field Field, opts FormatterOptions) {
 	// If the field is sensitive, use the redacted value
@@ -161,7 +288,7 @@ func formatJSONFieldValue(buf *bytes.Buffer, field Field, opts FormatterOptions)
 	case UintType, Uint64Type:
 		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(field.Integer), 10))
 	case Float32Type, Float64Type:
-		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), field.Float, 'f', -1, 64))
+		buf.Write(appendJSONFloat(buf.AvailableBuffer(), field.Float))
 	case StringType:
 		buf.WriteByte('"')
 		if opts.TruncateStrings > 0 && len(field.String) > opts.TruncateStrings {
@@ -217,7 +344,46 @@ func formatJSONFieldValue(buf *bytes.Buffer, field Field, opts FormatterOptions)
 			encodeBase64(buf, data)
 			buf.WriteByte('"')
 		}
+	case StackType:
+		stack, _ := field.Interface.(Stacktrace)
+		buf.WriteByte('[')
+		for i, frame := range stack {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("{\"function\":\"")
+			writeEscapedStringOptimized(buf, frame.Function)
+			buf.WriteString("\",\"file\":\"")
+			writeEscapedStringOptimized(buf, frame.File)
+			buf.WriteString("\",\"line\":")
+			buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(frame.Line), 10))
+			buf.WriteByte('}')
+		}
+		buf.WriteByte(']')
+	case StringerType, HexType, IPAddrType, URLType, UUIDType:
+		buf.WriteByte('"')
+		writeEscapedStringOptimized(buf, textValue(field))
+		buf.WriteByte('"')
+	case ByteSizeType:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), field.Integer, 10))
+	case PercentType:
+		buf.Write(appendJSONFloat(buf.AvailableBuffer(), field.Float))
 	default:
 		buf.WriteString("null")
 	}
-}
\ No newline at end of file
+}
+
+// appendJSONFloat appends f to dst as a JSON number. NaN and infinities,
+// which JSON numbers cannot hold, are appended as the strings "NaN", "+Inf"
+// and "-Inf".
+func appendJSONFloat(dst []byte, f float64) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(dst, `"NaN"`...)
+	case math.IsInf(f, 1):
+		return append(dst, `"+Inf"`...)
+	case math.IsInf(f, -1):
+		return append(dst, `"-Inf"`...)
+	}
+	return strconv.AppendFloat(dst, f, 'f', -1, 64)
+}
@@ -3,8 +3,9 @@ package onelog
 import (
 	"bytes"
 	"io"
-	"sort"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,7 +16,11 @@ type LogfmtFormatter struct {
 	Options FormatterOptions
 	// DisableQuoting disables quoting of values.
 	DisableQuoting bool
-	// DisableSorting disables sorting of fields.
+	// DisableSorting disables sorting of fields. The fields bound to a
+	// logger with WithFields are encoded once, so they are sorted among
+	// themselves and written before the entry's own fields, which are sorted
+	// in turn. Bound fields that cannot be encoded ahead of time, such as
+	// lazy and error fields, are sorted along with the entry's fields.
 	DisableSorting bool
 	// timeCache caches formatted time strings
 	timeCache *sync.Map
@@ -88,6 +93,22 @@ func (f *LogfmtFormatter) Format(w io.Writer, e *Entry) error {
 		}
 	}
 	
+	// Write the logger name
+	if e.name != "" && f.Options.NameKey != "" {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		writeEscapedLogfmtString(buf, f.Options.NameKey)
+		buf.WriteByte('=')
+		if !f.DisableQuoting {
+			buf.WriteByte('"')
+		}
+		writeEscapedLogfmtString(buf, e.name)
+		if !f.DisableQuoting {
+			buf.WriteByte('"')
+		}
+	}
+	
 	// Write the message
 	if e.message != "" {
 		if buf.Len() > 0 {
@@ -114,34 +135,60 @@ func (f *LogfmtFormatter) Format(w io.Writer, e *Entry) error {
 		if !f.DisableQuoting {
 			buf.WriteByte('"')
 		}
-		writeEscapedLogfmtString(buf, e.callerInfo.File)
-		buf.WriteByte(':')
-		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(e.callerInfo.Line), 10))
+		if f.Options.CallerEncoder != nil {
+			writeEscapedLogfmtString(buf, f.Options.CallerEncoder(e.callerInfo))
+		} else {
+			writeEscapedLogfmtString(buf, e.callerInfo.File)
+			buf.WriteByte(':')
+			buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(e.callerInfo.Line), 10))
+		}
 		if !f.DisableQuoting {
 			buf.WriteByte('"')
 		}
 	}
 	
+	// Write the trace correlation fields
+	traceFields, n := e.traceFields(f.Options.TraceKeys)
+	for _, field := range traceFields[:n] {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		f.writeTraceField(buf, field)
+	}
+	
+	// Write the pre-encoded fields bound to the logger
+	if len(e.encodedFields) > 0 {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.Write(e.encodedFields)
+	}
+	
 	// Get the fields
-	fields := e.fields
-	if !f.DisableSorting && len(fields) > 1 {
-		sort.Slice(fields, func(i, j int) bool {
-			return fields[i].Key < fields[j].Key
-		})
+	fields := applyKeyPolicies(e.fields, e.level, &f.Options)
+	if !f.DisableSorting {
+		fields = sortFieldsByKey(fields)
 	}
 	
 	// Write the fields
 	for _, field := range fields {
+		if isStackBlock(field) {
+			continue
+		}
 		if buf.Len() > 0 {
 			buf.WriteByte(' ')
 		}
-		
-		// Write the field key
-		writeEscapedLogfmtString(buf, f.Options.FieldNameConverter(field.Key))
-		buf.WriteByte('=')
-		
-		// Format the field value
-		f.formatFieldValue(buf, field)
+		f.writeField(buf, field)
+		if errorDetailsEnabled(field, e.level, f.Options) {
+			f.writeErrorDetails(buf, field)
+		}
+	}
+	
+	// Write the stack traces as indented blocks below the line
+	for _, field := range fields {
+		if isStackBlock(field) {
+			f.writeStack(buf, field)
+		}
 	}
 	
 	// Add a newline if not disabled
@@ -154,6 +201,101 @@ func (f *LogfmtFormatter) Format(w io.Writer, e *Entry) error {
 	return err
 }
 
+// sortFieldsByKey returns fields sorted by key, keeping the order of fields
+// that share a key. fields is not modified, since it may be the fields of an
+// entry written by several cores; a copy is only allocated if it is not
+// already sorted.
+func sortFieldsByKey(fields []Field) []Field {
+	less := func(a, b Field) int {
+		return strings.Compare(a.Key, b.Key)
+	}
+	if slices.IsSortedFunc(fields, less) {
+		return fields
+	}
+	sorted := slices.Clone(fields)
+	slices.SortStableFunc(sorted, less)
+	return sorted
+}
+
+// EncodeFields implements FieldEncoder.
+func (f *LogfmtFormatter) EncodeFields(buf *bytes.Buffer, fields []Field) {
+	if !f.DisableSorting {
+		fields = sortFieldsByKey(fields)
+	}
+
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		f.writeField(buf, field)
+	}
+}
+
+// writeField writes a single key=value pair.
+func (f *LogfmtFormatter) writeField(buf *bytes.Buffer, field Field) {
+	// Flatten marshaled objects and arrays into dotted keys
+	if !field.IsSensitive {
+		field = resolveAny(field, false)
+		if obj, arr := marshalers(field, &f.Options); obj != nil || arr != nil {
+			f.writeMarshaled(buf, f.Options.FieldNameConverter(field.Key), obj, arr)
+			return
+		}
+	}
+
+	// Write the field key
+	writeEscapedLogfmtString(buf, f.Options.FieldNameConverter(field.Key))
+	buf.WriteByte('=')
+
+	// Format the field value
+	f.formatFieldValue(buf, field)
+}
+
+// writeTraceField writes a trace correlation field. Its key is written as set
+// in TraceKeys, without the FieldNameConverter.
+func (f *LogfmtFormatter) writeTraceField(buf *bytes.Buffer, field Field) {
+	writeEscapedLogfmtString(buf, field.Key)
+	buf.WriteByte('=')
+	f.formatFieldValue(buf, field)
+}
+
+// writeErrorDetails writes the details of an error field as the sibling
+// fields key.type, key.chain.N, key.errors.N and key.stack.
+func (f *LogfmtFormatter) writeErrorDetails(buf *bytes.Buffer, field Field) {
+	details := describeError(field.Interface.(error))
+	key := f.Options.FieldNameConverter(field.Key)
+
+	f.writeDetail(buf, key+".type", details.typ)
+	for i, message := range details.chain {
+		f.writeDetail(buf, key+".chain."+strconv.Itoa(i), message)
+	}
+	for i, message := range details.members {
+		f.writeDetail(buf, key+".errors."+strconv.Itoa(i), message)
+	}
+	if details.stack != "" {
+		f.writeDetail(buf, key+".stack", details.stack)
+	}
+}
+
+// writeDetail writes a detail field as a key="value" pair.
+func (f *LogfmtFormatter) writeDetail(buf *bytes.Buffer, key, value string) {
+	buf.WriteByte(' ')
+	writeEscapedLogfmtString(buf, key)
+	buf.WriteByte('=')
+	if !f.DisableQuoting {
+		buf.WriteByte('"')
+	}
+	writeEscapedLogfmtString(buf, value)
+	if !f.DisableQuoting {
+		buf.WriteByte('"')
+	}
+}
+
+// writeStack writes a stack trace field as an indented block.
+func (f *LogfmtFormatter) writeStack(buf *bytes.Buffer, field Field) {
+	stack, _ := field.Interface.(Stacktrace)
+	writeStackBlock(buf, f.Options.FieldNameConverter(field.Key), stack)
+}
+
 // formatFieldValue formats a field value for logfmt.
 func (f *LogfmtFormatter) formatFieldValue(buf *bytes.Buffer, field Field) {
 	// If the field is sensitive, use the redacted value
@@ -228,7 +370,7 @@ func (f *LogfmtFormatter) formatFieldValue(buf *bytes.Buffer, field Field) {
 		if !f.DisableQuoting {
 			buf.WriteByte('"')
 		}
-	case ObjectType, ArrayType, BinaryType:
+	case ObjectType, ArrayType, BinaryType, StackType:
 		if !f.DisableQuoting {
 			buf.WriteByte('"')
 		}
@@ -236,6 +378,18 @@ func (f *LogfmtFormatter) formatFieldValue(buf *bytes.Buffer, field Field) {
 		if !f.DisableQuoting {
 			buf.WriteByte('"')
 		}
+	case StringerType, HexType, IPAddrType, URLType, UUIDType:
+		if !f.DisableQuoting {
+			buf.WriteByte('"')
+		}
+		writeEscapedLogfmtString(buf, textValue(field))
+		if !f.DisableQuoting {
+			buf.WriteByte('"')
+		}
+	case ByteSizeType:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), field.Integer, 10))
+	case PercentType:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), field.Float, 'f', -1, 64))
 	default:
 		buf.WriteString("null")
 	}
@@ -0,0 +1,85 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSortingFormattersDoNotReorderEntryFields(t *testing.T) {
+	var logfmtBuf, jsonBuf bytes.Buffer
+	logger := New(NewConfig(WithCore(NewTee(
+		NewCore(NewLogfmtFormatter(), &logfmtBuf),
+		NewCore(NewJSONFormatter(), &jsonBuf),
+	))))
+	logger.Info("hi", Str("b", "2"), Str("a", "1"))
+
+	if !strings.Contains(logfmtBuf.String(), `a="1" b="2"`) {
+		t.Errorf("logfmt output not sorted: %s", logfmtBuf.String())
+	}
+	if !strings.Contains(jsonBuf.String(), `"b":"2","a":"1"`) {
+		t.Errorf("JSON output reordered by the logfmt core: %s", jsonBuf.String())
+	}
+}
+
+func TestSortFieldsByKey(t *testing.T) {
+	fields := []Field{Str("b", "2"), Str("a", "1"), Str("a", "0")}
+	sorted := sortFieldsByKey(fields)
+	if got := strings.Join(fieldKeys(sorted), " "); got != "a a b" || sorted[0].String != "1" {
+		t.Errorf("sorted = %+v", sorted)
+	}
+	if fields[0].Key != "b" {
+		t.Error("sortFieldsByKey modified its input")
+	}
+}
+
+func TestSortingFormattersWriteBoundFieldsFirst(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewLogfmtFormatter()))).
+		WithFields(Str("z", "bound"), Str("y", "bound"))
+	logger.Info("hi", Str("b", "own"), Str("a", "own"))
+
+	if !strings.Contains(buf.String(), `y="bound" z="bound" a="own" b="own"`) {
+		t.Errorf("output = %s", buf.String())
+	}
+}
+
+func TestJSONFormatterWritesNonFiniteFloatsAsStrings(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+	logger.Info("floats",
+		Float64("nan", math.NaN()),
+		Float64("pos", math.Inf(1)),
+		Float64("neg", math.Inf(-1)),
+		Percent("pct", math.Inf(1)),
+		Dict("d", Float64("nan", math.NaN())),
+	)
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	want := map[string]any{"nan": "NaN", "pos": "+Inf", "neg": "-Inf", "pct": "+Inf"}
+	for key, value := range want {
+		if out[key] != value {
+			t.Errorf("%s = %v, want %v", key, out[key], value)
+		}
+	}
+	if d, _ := out["d"].(map[string]any); d["nan"] != "NaN" {
+		t.Errorf("d = %v", out["d"])
+	}
+}
+
+func TestTextFormatterQuotesByteSize(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewTextFormatter()
+	formatter.ForceQuote = true
+	formatter.EnableColors = false
+	New(NewConfig(WithWriter(&buf), WithFormatter(formatter))).Info("size", ByteSize("size", 1536))
+
+	if !strings.Contains(buf.String(), `size="`) {
+		t.Errorf("byte size is not quoted: %s", buf.String())
+	}
+}
@@ -3,7 +3,6 @@ package onelog
 import (
 	"bytes"
 	"io"
-	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -17,7 +16,11 @@ type TextFormatter struct {
 	FieldSeparator string
 	// EnableColors enables colored output.
 	EnableColors bool
-	// DisableSorting disables sorting of fields.
+	// DisableSorting disables sorting of fields. The fields bound to a
+	// logger with WithFields are encoded once, so they are sorted among
+	// themselves and written before the entry's own fields, which are sorted
+	// in turn. Bound fields that cannot be encoded ahead of time, such as
+	// lazy and error fields, are sorted along with the entry's fields.
 	DisableSorting bool
 	// EnableFieldNames enables field names in the output.
 	EnableFieldNames bool
@@ -85,41 +88,60 @@ func (f *TextFormatter) Format(w io.Writer, e *Entry) error {
 		buf.WriteString(f.FieldSeparator)
 	}
 
+	// Write the logger name
+	if e.name != "" && f.Options.NameKey != "" {
+		buf.WriteByte('[')
+		buf.WriteString(e.name)
+		buf.WriteByte(']')
+		buf.WriteString(f.FieldSeparator)
+	}
+
+	// Write the caller info
+	if e.callerInfo != nil {
+		encodeCaller := f.Options.CallerEncoder
+		if encodeCaller == nil {
+			encodeCaller = ShortCallerEncoder
+		}
+		buf.WriteString(encodeCaller(e.callerInfo))
+		buf.WriteString(f.FieldSeparator)
+	}
+
 	// Write the message
 	buf.WriteString(e.message)
 
 	// Get the fields
-	fields := e.fields
-	if !f.DisableSorting && len(fields) > 1 {
-		sort.Slice(fields, func(i, j int) bool {
-			return fields[i].Key < fields[j].Key
-		})
+	fields := applyKeyPolicies(e.fields, e.level, &f.Options)
+	if !f.DisableSorting {
+		fields = sortFieldsByKey(fields)
 	}
 
-	// Write the fields
-	if len(fields) > 0 {
+	// Write the trace correlation fields
+	traceFields, n := e.traceFields(f.Options.TraceKeys)
+	for _, field := range traceFields[:n] {
 		buf.WriteString(f.FieldSeparator)
+		f.writeTraceField(buf, field)
 	}
 
-	for i, field := range fields {
-		if i > 0 {
-			buf.WriteString(f.FieldSeparator)
-		}
+	// Write the pre-encoded fields bound to the logger
+	if len(e.encodedFields) > 0 {
+		buf.WriteString(f.FieldSeparator)
+		buf.Write(e.encodedFields)
+	}
 
-		// Write the field name if enabled
-		if f.EnableFieldNames {
-			if f.EnableColors {
-				buf.WriteString(keyColor)
-			}
-			buf.WriteString(f.Options.FieldNameConverter(field.Key))
-			buf.WriteString("=")
-			if f.EnableColors {
-				buf.WriteString(resetColor)
-			}
+	// Write the fields
+	for _, field := range fields {
+		if isStackBlock(field) {
+			continue
 		}
+		buf.WriteString(f.FieldSeparator)
+		f.writeField(buf, field)
+	}
 
-		// Format the field value
-		f.formatFieldValue(buf, field)
+	// Write the stack traces as indented blocks below the line
+	for _, field := range fields {
+		if isStackBlock(field) {
+			f.writeStack(buf, field)
+		}
 	}
 
 	// Add a newline if not disabled
@@ -132,6 +154,64 @@ func (f *TextFormatter) Format(w io.Writer, e *Entry) error {
 	return err
 }
 
+// EncodeFields implements FieldEncoder.
+func (f *TextFormatter) EncodeFields(buf *bytes.Buffer, fields []Field) {
+	if !f.DisableSorting {
+		fields = sortFieldsByKey(fields)
+	}
+
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteString(f.FieldSeparator)
+		}
+		f.writeField(buf, field)
+	}
+}
+
+// writeField writes a single field, with its name if enabled.
+func (f *TextFormatter) writeField(buf *bytes.Buffer, field Field) {
+	if !field.IsSensitive {
+		field = resolveAny(field, false)
+	}
+
+	// Write the field name if enabled
+	if f.EnableFieldNames {
+		if f.EnableColors {
+			buf.WriteString(keyColor)
+		}
+		buf.WriteString(f.Options.FieldNameConverter(field.Key))
+		buf.WriteString("=")
+		if f.EnableColors {
+			buf.WriteString(resetColor)
+		}
+	}
+
+	// Format the field value
+	f.formatFieldValue(buf, field)
+}
+
+// writeTraceField writes a trace correlation field. Its key is written as set
+// in TraceKeys, without the FieldNameConverter.
+func (f *TextFormatter) writeTraceField(buf *bytes.Buffer, field Field) {
+	if f.EnableFieldNames {
+		if f.EnableColors {
+			buf.WriteString(keyColor)
+		}
+		buf.WriteString(field.Key)
+		buf.WriteString("=")
+		if f.EnableColors {
+			buf.WriteString(resetColor)
+		}
+	}
+	f.formatFieldValue(buf, field)
+}
+
+// writeStack writes a stack trace field as an indented block.
+func (f *TextFormatter) writeStack(buf *bytes.Buffer, field Field) {
+	stack, _ := field.Interface.(Stacktrace)
+	writeStackBlock(buf, f.Options.FieldNameConverter(field.Key), stack)
+}
+
 // formatFieldValue formats a field value.
 func (f *TextFormatter) formatFieldValue(buf *bytes.Buffer, field Field) {
 	// If the field is sensitive, use the redacted value
@@ -230,18 +310,50 @@ func (f *TextFormatter) formatFieldValue(buf *bytes.Buffer, field Field) {
 		if f.ForceQuote {
 			buf.WriteString("\"")
 		}
-	case ObjectType, ArrayType, BinaryType:
+	case ObjectType, ArrayType, BinaryType, StackType:
 		if f.EnableColors {
 			buf.WriteString(defaultColor)
 		}
 		if f.ForceQuote {
 			buf.WriteString("\"")
 		}
-		// Use stringifyValue helper for consistent formatting
-		buf.WriteString(stringifyValue(field.Interface))
+		if obj, arr := marshalers(field, &f.Options); obj != nil || arr != nil {
+			writeTextMarshaled(buf, &f.Options, obj, arr)
+		} else {
+			// Use stringifyValue helper for consistent formatting
+			buf.WriteString(stringifyValue(field.Interface))
+		}
 		if f.ForceQuote {
 			buf.WriteString("\"")
 		}
+	case StringerType, HexType, IPAddrType, URLType, UUIDType:
+		if f.EnableColors {
+			buf.WriteString(stringColor)
+		}
+		if f.ForceQuote {
+			buf.WriteString("\"")
+		}
+		buf.Write(appendTextValue(buf.AvailableBuffer(), field))
+		if f.ForceQuote {
+			buf.WriteString("\"")
+		}
+	case ByteSizeType:
+		if f.EnableColors {
+			buf.WriteString(numberColor)
+		}
+		if f.ForceQuote {
+			buf.WriteString("\"")
+		}
+		buf.Write(appendByteSize(buf.AvailableBuffer(), field.Integer))
+		if f.ForceQuote {
+			buf.WriteString("\"")
+		}
+	case PercentType:
+		if f.EnableColors {
+			buf.WriteString(numberColor)
+		}
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), field.Float, 'f', -1, 64))
+		buf.WriteByte('%')
 	default:
 		buf.WriteString("null")
 	}
@@ -0,0 +1,207 @@
+package onelog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DuplicateKeyPolicy controls how formatters write fields that share a key.
+type DuplicateKeyPolicy uint8
+
+const (
+	// KeepDuplicateKeys writes every field, even if its key is repeated.
+	KeepDuplicateKeys DuplicateKeyPolicy = iota
+	// LastKeyWins writes only the last field added with a key.
+	LastKeyWins
+	// FirstKeyWins writes only the first field added with a key.
+	FirstKeyWins
+	// SuffixDuplicateKeys writes every field, renaming repeated keys with a
+	// counter: user_id, user_id_1, user_id_2.
+	SuffixDuplicateKeys
+)
+
+// ReservedKeyPolicy controls how formatters write fields whose key is one
+// of the keys of the entry itself: TimeKey, LevelKey, MessageKey, CallerKey,
+// NameKey, the TraceKeys, "stacktrace", and the "<key>.type", "<key>.chain",
+// "<key>.errors" and "<key>.stack" keys of the details of an error field.
+type ReservedKeyPolicy uint8
+
+const (
+	// KeepReservedKeys writes such fields under their key.
+	KeepReservedKeys ReservedKeyPolicy = iota
+	// PrefixReservedKeys writes such fields under their key prefixed with
+	// "fields.", such as fields.time.
+	PrefixReservedKeys
+	// RenameReservedKeys writes such fields under their key prefixed with an
+	// underscore, such as _time.
+	RenameReservedKeys
+)
+
+// reservedKeyPrefix returns the prefix added to reserved keys by policy.
+func reservedKeyPrefix(policy ReservedKeyPolicy) string {
+	switch policy {
+	case PrefixReservedKeys:
+		return "fields."
+	case RenameReservedKeys:
+		return "_"
+	}
+	return ""
+}
+
+// optionsFormatter is implemented by the built-in formatters.
+type optionsFormatter interface {
+	formatterOptions() *FormatterOptions
+}
+
+// formatterOptions implements optionsFormatter.
+func (f *JSONFormatter) formatterOptions() *FormatterOptions {
+	return &f.Options
+}
+
+// formatterOptions implements optionsFormatter.
+func (f *LogfmtFormatter) formatterOptions() *FormatterOptions {
+	return &f.Options
+}
+
+// formatterOptions implements optionsFormatter.
+func (f *TextFormatter) formatterOptions() *FormatterOptions {
+	return &f.Options
+}
+
+// keyPoliciesActive returns whether formatter applies a duplicate or
+// reserved key policy. Fields bound to a logger are then not pre-encoded,
+// since the policies apply across bound and entry fields.
+func keyPoliciesActive(formatter Formatter) bool {
+	f, ok := formatter.(optionsFormatter)
+	if !ok {
+		return false
+	}
+	opts := f.formatterOptions()
+	return opts.DuplicateKeys != KeepDuplicateKeys || opts.ReservedKeyPolicy != KeepReservedKeys
+}
+
+// applyKeyPolicies returns the fields of an entry at the given level to
+// write under the duplicate and reserved key policies of opts. fields is not
+// modified; a new slice is only allocated if a policy changes something.
+func applyKeyPolicies(fields []Field, level Level, opts *FormatterOptions) []Field {
+	if opts.ReservedKeyPolicy != KeepReservedKeys {
+		fields = renameReservedKeys(fields, level, opts)
+	}
+	if opts.DuplicateKeys != KeepDuplicateKeys && hasDuplicateKeys(fields) {
+		fields = resolveDuplicateKeys(fields, opts.DuplicateKeys)
+	}
+	return fields
+}
+
+// errorDetailSuffixes are the suffixes of the keys of error details.
+var errorDetailSuffixes = [...]string{".type", ".chain", ".errors", ".stack"}
+
+// isReservedKey returns whether the key of field, one of the fields of an
+// entry at the given level, is one of the entry's own keys.
+func isReservedKey(field Field, fields []Field, level Level, opts *FormatterOptions) bool {
+	switch field.Key {
+	case "":
+		return false
+	case opts.TimeKey, opts.LevelKey, opts.MessageKey, opts.CallerKey, opts.NameKey,
+		opts.TraceKeys.TraceID, opts.TraceKeys.SpanID, opts.TraceKeys.TraceFlags:
+		return true
+	case stacktraceKey:
+		return field.Type != StackType
+	}
+	return isErrorDetailKey(field.Key, fields, level, opts)
+}
+
+// isErrorDetailKey returns whether key is the key of a detail of one of the
+// error fields of an entry at the given level.
+func isErrorDetailKey(key string, fields []Field, level Level, opts *FormatterOptions) bool {
+	for _, suffix := range errorDetailSuffixes {
+		prefix, ok := strings.CutSuffix(key, suffix)
+		if !ok {
+			continue
+		}
+		for i := range fields {
+			if fields[i].Key == prefix && errorDetailsEnabled(fields[i], level, *opts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renameReservedKeys prefixes the keys of the fields that use a reserved key.
+func renameReservedKeys(fields []Field, level Level, opts *FormatterOptions) []Field {
+	var renamed []Field
+	for i := range fields {
+		if !isReservedKey(fields[i], fields, level, opts) {
+			continue
+		}
+		if renamed == nil {
+			renamed = make([]Field, len(fields))
+			copy(renamed, fields)
+		}
+		renamed[i].Key = reservedKeyPrefix(opts.ReservedKeyPolicy) + fields[i].Key
+	}
+	if renamed == nil {
+		return fields
+	}
+	return renamed
+}
+
+// hasDuplicateKeys returns whether two fields share a key.
+func hasDuplicateKeys(fields []Field) bool {
+	for i := 1; i < len(fields); i++ {
+		for j := 0; j < i; j++ {
+			if fields[i].Key == fields[j].Key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveDuplicateKeys returns the fields with repeated keys dropped or
+// renamed according to policy, in their original order. A renamed key skips
+// the counters that would collide with the key of another field.
+func resolveDuplicateKeys(fields []Field, policy DuplicateKeyPolicy) []Field {
+	counts := make(map[string]int, len(fields))
+	for i := range fields {
+		counts[fields[i].Key]++
+	}
+
+	resolved := make([]Field, 0, len(fields))
+	seen := make(map[string]int, len(fields))
+	suffixes := make(map[string]int)
+	for _, field := range fields {
+		n := seen[field.Key]
+		seen[field.Key] = n + 1
+		switch policy {
+		case LastKeyWins:
+			if n+1 < counts[field.Key] {
+				continue
+			}
+		case FirstKeyWins:
+			if n > 0 {
+				continue
+			}
+		case SuffixDuplicateKeys:
+			if n > 0 {
+				field.Key = suffixKey(field.Key, suffixes, counts)
+			}
+		}
+		resolved = append(resolved, field)
+	}
+	return resolved
+}
+
+// suffixKey returns key with the next counter suffix that is not already
+// used, recording the new key in used.
+func suffixKey(key string, last, used map[string]int) string {
+	for n := last[key] + 1; ; n++ {
+		candidate := key + "_" + strconv.Itoa(n)
+		if used[candidate] == 0 {
+			last[key] = n
+			used[candidate]++
+			return candidate
+		}
+	}
+}
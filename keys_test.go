@@ -0,0 +1,101 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testSpan struct{}
+
+func (testSpan) SpanContext() (string, string, bool) {
+	return "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true
+}
+
+func fieldKeys(fields []Field) []string {
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		keys[i] = field.Key
+	}
+	return keys
+}
+
+func TestApplyKeyPoliciesDuplicates(t *testing.T) {
+	fields := []Field{Int("user_id", 1), Int("user_id_1", 2), Int("user_id", 3), Int("user_id", 4)}
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   string
+	}{
+		{KeepDuplicateKeys, "user_id user_id_1 user_id user_id"},
+		{LastKeyWins, "user_id_1 user_id"},
+		{FirstKeyWins, "user_id user_id_1"},
+		{SuffixDuplicateKeys, "user_id user_id_1 user_id_2 user_id_3"},
+	}
+	for _, tt := range tests {
+		opts := DefaultFormatterOptions()
+		opts.DuplicateKeys = tt.policy
+		got := applyKeyPolicies(fields, InfoLevel, &opts)
+		if keys := strings.Join(fieldKeys(got), " "); keys != tt.want {
+			t.Errorf("policy %d: keys = %q, want %q", tt.policy, keys, tt.want)
+		}
+		if tt.policy == LastKeyWins && got[1].Integer != 4 {
+			t.Errorf("LastKeyWins kept user_id=%d, want 4", got[1].Integer)
+		}
+	}
+	if fields[2].Key != "user_id" {
+		t.Error("applyKeyPolicies modified its input")
+	}
+}
+
+func TestApplyKeyPoliciesReserved(t *testing.T) {
+	fields := []Field{
+		Str("time", "t"), Str("msg", "m"), Str("trace_id", "x"), Str("stacktrace", "s"),
+		Err(errors.New("boom")), Str("error.type", "mine"), Str("user", "ann"),
+	}
+	opts := DefaultFormatterOptions()
+	opts.MessageKey = "msg"
+	opts.ReservedKeyPolicy = PrefixReservedKeys
+
+	got := strings.Join(fieldKeys(applyKeyPolicies(fields, ErrorLevel, &opts)), " ")
+	want := "fields.time fields.msg fields.trace_id fields.stacktrace error fields.error.type user"
+	if got != want {
+		t.Errorf("keys = %q, want %q", got, want)
+	}
+
+	// Error details are not written below ErrorDetailsLevel
+	got = strings.Join(fieldKeys(applyKeyPolicies(fields[4:], InfoLevel, &opts)), " ")
+	if want := "error error.type user"; got != want {
+		t.Errorf("keys below ErrorDetailsLevel = %q, want %q", got, want)
+	}
+
+	opts.ReservedKeyPolicy = RenameReservedKeys
+	stack := Field{Key: stacktraceKey, Type: StackType, Interface: Stacktrace{}}
+	got = strings.Join(fieldKeys(applyKeyPolicies([]Field{stack, Str("level", "l")}, InfoLevel, &opts)), " ")
+	if want := "stacktrace _level"; got != want {
+		t.Errorf("keys = %q, want %q", got, want)
+	}
+}
+
+func TestReservedTraceKeysProduceValidJSON(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewJSONFormatter()
+	formatter.Options.ReservedKeyPolicy = PrefixReservedKeys
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter)))
+
+	ctx := ContextWithSpan(context.Background(), testSpan{})
+	logger.WithContext(ctx).Str("trace_id", "mine").Info("hi")
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	if out["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || out["fields.trace_id"] != "mine" {
+		t.Errorf("output = %s", buf.String())
+	}
+	if strings.Count(buf.String(), `"trace_id"`) != 1 {
+		t.Errorf("duplicate trace_id key: %s", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+package onelog
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// lazyField holds the key and function of a lazy field.
+type lazyField struct {
+	key string
+	fn  func() Field
+}
+
+// Lazy creates a Field whose value is built by fn only when an entry
+// carrying it is written, after the level check, sampling and hooks. fn is
+// called at most once per entry written, however many cores the entry goes
+// to, so a lazy field bound to a logger with WithFields reflects the state at
+// the time of each entry. If fn panics, the field becomes an error field
+// describing the panic.
+func Lazy(key string, fn func() Field) Field {
+	return Field{
+		Key:  key,
+		Type: LazyType,
+		Interface: &lazyField{
+			key: key,
+			fn:  fn,
+		},
+	}
+}
+
+// LazyAny creates a Field whose value is returned by fn only when an entry
+// carrying it is written. It is the Any counterpart of Lazy.
+func LazyAny(key string, fn func() any) Field {
+	return Lazy(key, func() Field {
+		return Any(key, fn())
+	})
+}
+
+// resolve calls the field's function and returns its result.
+func (l *lazyField) resolve() (field Field) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("onelog: lazy field %q panicked: %v", l.key, r)
+			field = NamedErr(l.key, err)
+		}
+	}()
+	field = l.fn()
+	field.Key = l.key
+	return field
+}
+
+// LogValue implements slog.LogValuer, so lazy fields forwarded to a
+// slog.Handler are resolved only if the handler uses them.
+func (l *lazyField) LogValue() slog.Value {
+	return slogAttrFromField(l.resolve()).Value
+}
+
+// resolvedLazy is a lazy field built for an entry.
+type resolvedLazy struct {
+	lazy  *lazyField
+	field Field
+}
+
+// resolveLazy returns the field built by a lazy field, or the field itself
+// if it is not lazy. A Dict holding lazy fields is replaced by a copy holding
+// their values. Each lazy field is built at most once per entry.
+func (e *Entry) resolveLazy(field Field) Field {
+	switch field.Type {
+	case LazyType:
+		lazy, ok := field.Interface.(*lazyField)
+		if !ok {
+			return field
+		}
+		resolved, ok := e.lazyResolved(lazy)
+		if !ok {
+			resolved = lazy.resolve()
+			e.lazies = append(e.lazies, resolvedLazy{lazy: lazy, field: resolved})
+		}
+		resolved.IsSensitive = resolved.IsSensitive || field.IsSensitive
+		// A lazy field may build a Dict holding lazy fields
+		if resolved.Type == ObjectType {
+			return e.resolveLazy(resolved)
+		}
+		return resolved
+	case ObjectType:
+		group, ok := field.Interface.(dict)
+		if !ok || !hasLazy(group) {
+			return field
+		}
+		members := make(dict, len(group))
+		for i := range group {
+			members[i] = e.resolveLazy(group[i])
+		}
+		field.Interface = members
+	}
+	return field
+}
+
+// lazyResolved returns the field already built for lazy by the entry, if any.
+func (e *Entry) lazyResolved(lazy *lazyField) (Field, bool) {
+	for i := range e.lazies {
+		if e.lazies[i].lazy == lazy {
+			return e.lazies[i].field, true
+		}
+	}
+	return Field{}, false
+}
+
+// hasLazy returns whether fields hold a lazy field, even within a Dict.
+func hasLazy(fields []Field) bool {
+	for i := range fields {
+		switch fields[i].Type {
+		case LazyType:
+			return true
+		case ObjectType:
+			if group, ok := fields[i].Interface.(dict); ok && hasLazy(group) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveLazyFields replaces the entry's lazy fields, including those within
+// a Dict, with their values.
+func (e *Entry) resolveLazyFields() {
+	for i := range e.fields {
+		switch e.fields[i].Type {
+		case LazyType, ObjectType:
+			e.fields[i] = e.resolveLazy(e.fields[i])
+		}
+	}
+}
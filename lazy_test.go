@@ -0,0 +1,91 @@
+package onelog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBoundLazyFieldResolvedPerEntry(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config func(*bytes.Buffer) *Config
+	}{
+		{"writer", func(buf *bytes.Buffer) *Config {
+			return NewConfig(WithWriter(buf), WithFormatter(NewJSONFormatter()))
+		}},
+		{"core", func(buf *bytes.Buffer) *Config {
+			return NewConfig(WithCore(NewCore(NewJSONFormatter(), buf)))
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			calls := 0
+			logger := New(tt.config(&buf)).WithFields(Lazy("n", func() Field {
+				calls++
+				return Int("", calls)
+			}))
+			logger.Info("first")
+			logger.Info("second")
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 || !strings.Contains(lines[0], `"n":1`) || !strings.Contains(lines[1], `"n":2`) {
+				t.Errorf("output = %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestLazyErrorGetsDetails(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithCore(NewCore(NewJSONFormatter(), &buf)))).
+		WithFields(Lazy("error", func() Field { return Err(errors.New("boom")) }))
+	logger.Error("failed")
+
+	if !strings.Contains(buf.String(), `"error.type":"*errors.errorString"`) {
+		t.Errorf("lazy error has no details: %s", buf.String())
+	}
+}
+
+func TestLazyFieldResolvedOncePerEntry(t *testing.T) {
+	var jsonBuf, logfmtBuf bytes.Buffer
+	core := NewTee(NewCore(NewJSONFormatter(), &jsonBuf), NewCore(NewLogfmtFormatter(), &logfmtBuf))
+	calls, boundCalls := 0, 0
+	logger := New(NewConfig(WithCore(core))).WithFields(Dict("b", Lazy("k", func() Field {
+		boundCalls++
+		return Int("", boundCalls)
+	})))
+
+	logger.Info("tee", Dict("d", Lazy("k", func() Field {
+		calls++
+		return Str("", "v")
+	})))
+
+	if calls != 1 {
+		t.Errorf("entry lazy called %d times, want 1", calls)
+	}
+	if boundCalls != 1 {
+		t.Errorf("bound lazy called %d times, want 1", boundCalls)
+	}
+	if !strings.Contains(jsonBuf.String(), `"d":{"k":"v"}`) || !strings.Contains(jsonBuf.String(), `"b":{"k":1}`) {
+		t.Errorf("json output = %s", jsonBuf.String())
+	}
+	if !strings.Contains(logfmtBuf.String(), `d.k="v"`) || !strings.Contains(logfmtBuf.String(), `b.k=1`) {
+		t.Errorf("logfmt output = %s", logfmtBuf.String())
+	}
+}
+
+func TestLazyDictIsNotMutated(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+	group := Dict("d", Lazy("k", func() Field { return Str("", "v") }))
+
+	logger.Info("first", group)
+	if members := group.Interface.(dict); members[0].Type != LazyType {
+		t.Errorf("dict member was replaced by %v", members[0].Type)
+	}
+	if !strings.Contains(buf.String(), `"d":{"k":"v"}`) {
+		t.Errorf("output = %s", buf.String())
+	}
+}
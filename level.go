@@ -4,17 +4,20 @@ package onelog
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 	"sync/atomic"
 )
 
 // Level represents the severity level of a log message.
 type Level uint32
 
-// Log levels.
+// Log levels. The built-in levels are spaced apart so that custom levels
+// registered with RegisterLevel can sit between them.
 const (
 	// TraceLevel is the lowest level, used for detailed debugging information.
-	TraceLevel Level = iota
+	TraceLevel Level = iota * 10
 	// DebugLevel is used for debugging information.
 	DebugLevel
 	// InfoLevel is used for general operational information.
@@ -23,21 +26,29 @@ const (
 	WarnLevel
 	// ErrorLevel is used for errors that should be addressed.
 	ErrorLevel
+	// DPanicLevel is used for errors that should never happen. In development
+	// the logger panics after writing the entry; otherwise it only logs it.
+	DPanicLevel
+	// PanicLevel is used for errors after which the logger panics.
+	PanicLevel
 	// FatalLevel is used for critical errors that require immediate attention.
 	// Logging at this level typically calls os.Exit(1).
 	FatalLevel
-	// Disabled turns off all logging.
-	Disabled
 )
 
-var levelNames = [...]string{
-	TraceLevel: "TRACE",
-	DebugLevel: "DEBUG",
-	InfoLevel:  "INFO",
-	WarnLevel:  "WARN",
-	ErrorLevel: "ERROR",
-	FatalLevel: "FATAL",
-	Disabled:   "DISABLED",
+// Disabled turns off all logging. It is above every built-in and custom level.
+const Disabled Level = math.MaxUint32
+
+var levelNames = map[Level]string{
+	TraceLevel:  "TRACE",
+	DebugLevel:  "DEBUG",
+	InfoLevel:   "INFO",
+	WarnLevel:   "WARN",
+	ErrorLevel:  "ERROR",
+	DPanicLevel: "DPANIC",
+	PanicLevel:  "PANIC",
+	FatalLevel:  "FATAL",
+	Disabled:    "DISABLED",
 }
 
 var levelMap = map[string]Level{
@@ -46,23 +57,111 @@ var levelMap = map[string]Level{
 	"INFO":     InfoLevel,
 	"WARN":     WarnLevel,
 	"ERROR":    ErrorLevel,
+	"DPANIC":   DPanicLevel,
+	"PANIC":    PanicLevel,
 	"FATAL":    FatalLevel,
 	"DISABLED": Disabled,
 }
 
+// levelTable holds the names, values and colors of all known levels. It is
+// replaced as a whole when a level is registered, so lookups never lock.
+type levelTable struct {
+	names  map[Level]string
+	values map[string]Level
+	colors map[Level]string
+}
+
+var (
+	levels   atomic.Pointer[levelTable]
+	levelsMu sync.Mutex
+)
+
+func init() {
+	levels.Store(&levelTable{
+		names:  levelNames,
+		values: levelMap,
+		colors: map[Level]string{},
+	})
+}
+
+// RegisterLevel registers a custom level with the given name, value and color.
+// The name is case-insensitive and is written in upper case. Registering the
+// same name and value again only updates the color; reusing a name or value
+// that belongs to another level is an error.
+func RegisterLevel(name string, value Level, color Color) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("%w: empty level name", ErrInvalidLevel)
+	}
+	if value == Disabled {
+		return fmt.Errorf("%w: level %s uses the Disabled value", ErrInvalidLevel, name)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	current := levels.Load()
+	if existing, ok := current.values[name]; ok && existing != value {
+		return fmt.Errorf("%w: level %s is already registered as %d", ErrInvalidLevel, name, existing)
+	}
+	if existing, ok := current.names[value]; ok && existing != name {
+		return fmt.Errorf("%w: level %d is already registered as %s", ErrInvalidLevel, value, existing)
+	}
+
+	table := current.clone()
+	table.names[value] = name
+	table.values[name] = value
+	if color != "" {
+		table.colors[value] = string(color)
+	}
+
+	levels.Store(table)
+	return nil
+}
+
+// setCustomLevelColor sets the color of a custom level.
+func setCustomLevelColor(level Level, color Color) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	table := levels.Load().clone()
+	table.colors[level] = string(color)
+	levels.Store(table)
+}
+
+// clone returns a copy of the table that can be modified.
+func (t *levelTable) clone() *levelTable {
+	clone := &levelTable{
+		names:  make(map[Level]string, len(t.names)+1),
+		values: make(map[string]Level, len(t.values)+1),
+		colors: make(map[Level]string, len(t.colors)+1),
+	}
+	for level, name := range t.names {
+		clone.names[level] = name
+	}
+	for name, level := range t.values {
+		clone.values[name] = level
+	}
+	for level, color := range t.colors {
+		clone.colors[level] = color
+	}
+	return clone
+}
+
 // String returns the string representation of the log level.
 func (l Level) String() string {
-	if l >= Level(len(levelNames)) {
-		return fmt.Sprintf("Level(%d)", l)
+	if name, ok := levels.Load().names[l]; ok {
+		return name
 	}
-	return levelNames[l]
+	return fmt.Sprintf("Level(%d)", l)
 }
 
-// ParseLevel parses a level string into a Level. It's case-insensitive.
+// ParseLevel parses a level string into a Level. It's case-insensitive and
+// accepts levels registered with RegisterLevel.
 // Returns an error if the level string is invalid.
 func ParseLevel(levelStr string) (Level, error) {
 	upperLevelStr := strings.ToUpper(levelStr)
-	level, ok := levelMap[upperLevelStr]
+	level, ok := levels.Load().values[upperLevelStr]
 	if !ok {
 		return InfoLevel, fmt.Errorf("unknown level: %s", levelStr)
 	}
@@ -0,0 +1,169 @@
+package onelog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelRegistry holds per-name level overrides for named loggers.
+// Names are dotted paths such as "db.pool"; a logger uses the level of the
+// most specific configured prefix of its name, or its own level if none is
+// configured.
+type LevelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]*AtomicLevel
+	// version is bumped whenever a name is added or removed, so named
+	// loggers know when to resolve their level again.
+	version uint64
+}
+
+// NewLevelRegistry creates a new, empty LevelRegistry.
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{
+		levels: make(map[string]*AtomicLevel),
+	}
+}
+
+// SetLevel sets the level for the given name and every name below it that
+// has no more specific override.
+func (r *LevelRegistry) SetLevel(name string, level Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if atomicLevel, ok := r.levels[name]; ok {
+		atomicLevel.SetLevel(level)
+		return
+	}
+	r.levels[name] = NewAtomicLevel(level)
+	atomic.AddUint64(&r.version, 1)
+}
+
+// Unset removes the override for the given name.
+func (r *LevelRegistry) Unset(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.levels[name]; ok {
+		delete(r.levels, name)
+		atomic.AddUint64(&r.version, 1)
+	}
+}
+
+// Lookup returns the AtomicLevel of the most specific configured prefix of
+// name. It returns false if neither name nor any of its prefixes is configured.
+func (r *LevelRegistry) Lookup(name string) (*AtomicLevel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for {
+		if atomicLevel, ok := r.levels[name]; ok {
+			return atomicLevel, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return nil, false
+		}
+		name = name[:i]
+	}
+}
+
+// Levels returns a snapshot of the configured overrides.
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]Level, len(r.levels))
+	for name, atomicLevel := range r.levels {
+		levels[name] = atomicLevel.Level()
+	}
+	return levels
+}
+
+// Parse applies a comma-separated list of name=level overrides, such as
+// "db.pool=debug,http=warn". Nothing is applied if any entry is invalid.
+func (r *LevelRegistry) Parse(spec string) error {
+	parsed := make(map[string]Level)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, levelStr, ok := strings.Cut(part, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("%w: invalid override %q", ErrInvalidLevel, part)
+		}
+		level, err := ParseLevel(levelStr)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidLevel, err)
+		}
+		parsed[strings.TrimSpace(name)] = level
+	}
+
+	for name, level := range parsed {
+		r.SetLevel(name, level)
+	}
+	return nil
+}
+
+// String returns the overrides as a sorted name=level list.
+func (r *LevelRegistry) String() string {
+	levels := r.Levels()
+	names := make([]string, 0, len(levels))
+	for name := range levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(levels[name].String())
+	}
+	return b.String()
+}
+
+// namedLevel resolves the effective level of a named logger, caching the
+// result until the registry's set of names changes.
+type namedLevel struct {
+	registry *LevelRegistry
+	name     string
+	fallback *AtomicLevel
+	cached   atomic.Pointer[resolvedLevel]
+}
+
+// resolvedLevel is a level resolved against a given registry version.
+type resolvedLevel struct {
+	level   *AtomicLevel
+	version uint64
+}
+
+// newNamedLevel creates a namedLevel for the given name.
+func newNamedLevel(registry *LevelRegistry, name string, fallback *AtomicLevel) *namedLevel {
+	return &namedLevel{
+		registry: registry,
+		name:     name,
+		fallback: fallback,
+	}
+}
+
+// resolve returns the AtomicLevel currently in effect for the name.
+func (n *namedLevel) resolve() *AtomicLevel {
+	version := atomic.LoadUint64(&n.registry.version)
+	if cached := n.cached.Load(); cached != nil && cached.version == version {
+		return cached.level
+	}
+
+	atomicLevel, ok := n.registry.Lookup(n.name)
+	if !ok {
+		atomicLevel = n.fallback
+	}
+	n.cached.Store(&resolvedLevel{level: atomicLevel, version: version})
+	return atomicLevel
+}
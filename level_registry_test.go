@@ -0,0 +1,74 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestNamedSetLevelSetsExactOverride(t *testing.T) {
+	registry := NewLevelRegistry()
+	registry.SetLevel("db", WarnLevel)
+	logger := New(NewConfig(WithWriter(io.Discard), WithLevelRegistry(registry)))
+
+	db := logger.Named("db")
+	pool := db.Named("pool")
+	conn := db.Named("conn")
+	pool.SetLevel(DebugLevel)
+
+	if got := pool.GetLevel(); got != DebugLevel {
+		t.Errorf("db.pool level = %v, want %v", got, DebugLevel)
+	}
+	if got := conn.GetLevel(); got != WarnLevel {
+		t.Errorf("db.conn level = %v, want %v", got, WarnLevel)
+	}
+	if got := db.GetLevel(); got != WarnLevel {
+		t.Errorf("db level = %v, want %v", got, WarnLevel)
+	}
+	if got := registry.String(); got != "db=WARN,db.pool=DEBUG" {
+		t.Errorf("registry = %q", got)
+	}
+
+	// Without an override, the exact name gets one as well
+	http := logger.Named("http")
+	http.SetLevel(ErrorLevel)
+	if got := http.GetLevel(); got != ErrorLevel {
+		t.Errorf("http level = %v, want %v", got, ErrorLevel)
+	}
+	if got := registry.String(); got != "db=WARN,db.pool=DEBUG,http=ERROR" {
+		t.Errorf("registry = %q", got)
+	}
+}
+
+func TestNamedSetLevelKeepsRootAndSiblings(t *testing.T) {
+	root := New(NewConfig(WithWriter(io.Discard), WithLevel(InfoLevel)))
+	api := root.Named("api")
+	http := root.Named("http")
+	http.SetLevel(ErrorLevel)
+
+	if got := http.GetLevel(); got != ErrorLevel {
+		t.Errorf("http level = %v, want %v", got, ErrorLevel)
+	}
+	if got := root.GetLevel(); got != InfoLevel {
+		t.Errorf("root level = %v, want %v", got, InfoLevel)
+	}
+	if got := api.GetLevel(); got != InfoLevel {
+		t.Errorf("api level = %v, want %v", got, InfoLevel)
+	}
+}
+
+func TestNameKeyIsEscaped(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewJSONFormatter()
+	formatter.Options.NameKey = `log"ger`
+	New(NewConfig(WithWriter(&buf), WithFormatter(formatter))).Named(`db"x`).Info("hi")
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	if out[`log"ger`] != `db"x` {
+		t.Errorf("output = %s", buf.String())
+	}
+}
@@ -0,0 +1,115 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Custom levels registered for the tests, between the built-in levels.
+const (
+	noticeLevel   = InfoLevel + 5
+	criticalLevel = ErrorLevel + 5
+	auditLevel    = WarnLevel + 5
+)
+
+func registerTestLevels(t *testing.T) {
+	t.Helper()
+	for _, level := range []struct {
+		name  string
+		value Level
+		color Color
+	}{
+		{"NOTICE", noticeLevel, "\033[36m"},
+		{"critical", criticalLevel, "\033[35m"},
+		{"Audit", auditLevel, ""},
+	} {
+		if err := RegisterLevel(level.name, level.value, level.color); err != nil {
+			t.Fatalf("RegisterLevel(%s) = %v", level.name, err)
+		}
+	}
+}
+
+func TestRegisterLevelRoundTrips(t *testing.T) {
+	registerTestLevels(t)
+
+	for name, want := range map[string]Level{"NOTICE": noticeLevel, "CRITICAL": criticalLevel, "AUDIT": auditLevel} {
+		got, err := ParseLevel(strings.ToLower(name))
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", strings.ToLower(name), got, err, want)
+		}
+		if got.String() != name {
+			t.Errorf("%d.String() = %q, want %q", got, got.String(), name)
+		}
+	}
+	if !(InfoLevel < noticeLevel && noticeLevel < WarnLevel && criticalLevel < DPanicLevel) {
+		t.Errorf("custom levels are not between the built-in levels")
+	}
+}
+
+func TestRegisterLevelColor(t *testing.T) {
+	registerTestLevels(t)
+	defer func(enabled bool) { colorsEnabled = enabled }(colorsEnabled)
+	colorsEnabled = true
+
+	if got := getColorForLevel(noticeLevel); got != "\033[36m" {
+		t.Errorf("NOTICE color = %q", got)
+	}
+	if got := getColorForLevel(auditLevel); got != defaultColor {
+		t.Errorf("AUDIT color = %q, want the default color", got)
+	}
+}
+
+func TestRegisterLevelRejectsConflicts(t *testing.T) {
+	registerTestLevels(t)
+
+	for _, tt := range []struct {
+		name  string
+		value Level
+	}{
+		{"NOTICE", noticeLevel + 1},
+		{"INFO", noticeLevel + 1},
+		{"OTHER", noticeLevel},
+		{"OTHER", WarnLevel},
+		{"", noticeLevel + 1},
+		{"OTHER", Disabled},
+	} {
+		if err := RegisterLevel(tt.name, tt.value, ""); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("RegisterLevel(%q, %d) = %v, want ErrInvalidLevel", tt.name, tt.value, err)
+		}
+	}
+	if err := RegisterLevel("notice", noticeLevel, ""); err != nil {
+		t.Errorf("registering NOTICE again = %v", err)
+	}
+}
+
+func TestCustomLevelIsWrittenByEachFormatter(t *testing.T) {
+	registerTestLevels(t)
+
+	text := NewTextFormatter()
+	text.EnableColors = false
+	for name, formatter := range map[string]Formatter{
+		"json":   NewJSONFormatter(),
+		"logfmt": NewLogfmtFormatter(),
+		"text":   text,
+	} {
+		var buf bytes.Buffer
+		logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter), WithLevel(noticeLevel)))
+		logger.Info("dropped")
+		logger.Log(noticeLevel, "noticed")
+		logger.LogEvent(criticalLevel).Msg("critical")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 || !strings.Contains(lines[0], "NOTICE") || !strings.Contains(lines[1], "CRITICAL") {
+			t.Errorf("%s output = %s", name, buf.String())
+		}
+		if name == "json" {
+			var out map[string]any
+			if err := json.Unmarshal([]byte(lines[0]), &out); err != nil || out["level"] != "NOTICE" {
+				t.Errorf("json entry = %s, %v", lines[0], err)
+			}
+		}
+	}
+}
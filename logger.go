@@ -3,10 +3,13 @@ package onelog
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
+	"log/slog"
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 )
 
 // Logger is the main struct that provides logging functionality.
@@ -22,6 +25,57 @@ type Logger struct {
 	enableCaller bool
 	callerSkip   int
 	hooks        []Hook
+	// stacktraceLevel is the level at and above which entries get a stack trace.
+	stacktraceLevel Level
+	// boundFields are the fields attached by WithFields.
+	boundFields []Field
+	// boundEncoded holds boundFields pre-encoded by the formatter, or nil
+	// if the formatter does not implement FieldEncoder.
+	boundEncoded []byte
+	// name is the dotted name set by Named.
+	name string
+	// levelRegistry holds per-name level overrides.
+	levelRegistry *LevelRegistry
+	// namedLevel resolves the level override for name, if any.
+	namedLevel *namedLevel
+	// slogHandler, if set, receives entries instead of the formatter and writer.
+	slogHandler slog.Handler
+	// contextExtractors derive fields from an entry's context.
+	contextExtractors []func(context.Context) []Field
+	// development makes DPanic entries panic.
+	development bool
+	// onFatal runs after a fatal entry is written and the logger flushed.
+	onFatal FatalAction
+	// core, if set, receives entries instead of the formatter and writer.
+	core Core
+	// state is shared by the logger and every logger derived from it.
+	state *loggerState
+	// redactor marks sensitive fields, or is nil if redaction is disabled.
+	redactor *redactor
+}
+
+// loggerState is the lifecycle state shared by a logger and its clones.
+type loggerState struct {
+	closed atomic.Bool
+}
+
+// FatalAction is called after a fatal entry has been written and the logger
+// flushed. If it returns, the Fatal call returns to its caller.
+type FatalAction func(msg string)
+
+// ExitAction returns a FatalAction that exits the process with the given code.
+func ExitAction(code int) FatalAction {
+	return func(string) {
+		exit(code)
+	}
+}
+
+// PanicAction returns a FatalAction that panics with the message instead of
+// exiting, so fatal paths can be exercised in tests.
+func PanicAction() FatalAction {
+	return func(msg string) {
+		panic(msg)
+	}
 }
 
 // Hook is a function that is called for each log entry.
@@ -66,16 +120,33 @@ func init() {
 // New creates a new Logger with the given configuration.
 func New(config *Config) *Logger {
 	logger := &Logger{
-		level:        NewAtomicLevel(config.Level),
-		formatter:    config.Formatter,
-		writer:       config.Writer,
-		errorHandler: config.ErrorHandler,
-		fieldPool:    newFieldPool(1024),
-		EnableAsync:  config.EnableAsync,
-		sampler:      config.Sampler,
-		enableCaller: config.EnableCaller,
-		callerSkip:   config.CallerSkip,
-		hooks:        config.Hooks,
+		level:             NewAtomicLevel(config.Level),
+		formatter:         config.Formatter,
+		writer:            config.Writer,
+		errorHandler:      config.ErrorHandler,
+		fieldPool:         newFieldPool(1024),
+		EnableAsync:       config.EnableAsync,
+		sampler:           config.Sampler,
+		enableCaller:      config.EnableCaller,
+		callerSkip:        config.CallerSkip,
+		stacktraceLevel:   Disabled,
+		hooks:             config.Hooks,
+		levelRegistry:     config.LevelRegistry,
+		slogHandler:       config.SlogHandler,
+		contextExtractors: config.ContextExtractors,
+		development:       config.Development,
+		onFatal:           config.OnFatal,
+		core:              config.Core,
+		state:             &loggerState{},
+	}
+	if config.EnableStacktrace {
+		logger.stacktraceLevel = config.StacktraceLevel
+	}
+	if logger.levelRegistry == nil {
+		logger.levelRegistry = NewLevelRegistry()
+	}
+	if config.RedactSensitiveFields {
+		logger.redactor = newRedactor(SensitiveKeys, config.AdditionalSensitiveKeys)
 	}
 
 	// Set default values if not provided
@@ -116,6 +187,8 @@ func New(config *Config) *Logger {
 func (l *Logger) WithLevel(level Level) *Logger {
 	clone := *l
 	clone.level = NewAtomicLevel(level)
+	// An explicit level takes precedence over any per-name override
+	clone.namedLevel = nil
 	return &clone
 }
 
@@ -123,6 +196,7 @@ func (l *Logger) WithLevel(level Level) *Logger {
 func (l *Logger) WithFormatter(formatter Formatter) *Logger {
 	clone := *l
 	clone.formatter = formatter
+	clone.encodeBoundFields()
 	return &clone
 }
 
@@ -138,7 +212,7 @@ func (l *Logger) WithWriter(writer io.Writer) *Logger {
 		clone.asyncBuffer.SetBackpressureMode(l.asyncBuffer.backpressureMode)
 		clone.asyncBuffer.SetDynamicResize(l.asyncBuffer.dynamicResize)
 		clone.asyncBuffer.SetResizeThreshold(l.asyncBuffer.resizeThreshold)
-		clone.asyncBuffer.SetFlushInterval(l.asyncBuffer.flushInterval)
+		clone.asyncBuffer.SetFlushInterval(l.asyncBuffer.getFlushInterval())
 	}
 	return &clone
 }
@@ -174,6 +248,14 @@ func (l *Logger) WithCaller(enabled bool) *Logger {
 	return &clone
 }
 
+// WithStacktrace returns a new Logger that adds a stack trace to entries at
+// and above the given level. Disabled turns stack traces off.
+func (l *Logger) WithStacktrace(level Level) *Logger {
+	clone := *l
+	clone.stacktraceLevel = level
+	return &clone
+}
+
 // WithHook returns a new Logger with the given hook added.
 func (l *Logger) WithHook(hook Hook) *Logger {
 	clone := *l
@@ -184,6 +266,127 @@ func (l *Logger) WithHook(hook Hook) *Logger {
 	return &clone
 }
 
+// WithContextExtractor returns a new Logger with the given context extractor
+// added. Extractors run when an entry with a context is written, and the
+// fields they return are added to the entry.
+func (l *Logger) WithContextExtractor(extractor func(context.Context) []Field) *Logger {
+	clone := *l
+	clone.contextExtractors = make([]func(context.Context) []Field, 0, len(l.contextExtractors)+1)
+	clone.contextExtractors = append(clone.contextExtractors, l.contextExtractors...)
+	clone.contextExtractors = append(clone.contextExtractors, extractor)
+	return &clone
+}
+
+// Named returns a child Logger whose name is the given name appended to the
+// parent's name with a dot, so Named("db").Named("pool") is named "db.pool".
+// If the logger has a LevelRegistry, the child uses the level of the most
+// specific configured prefix of its name.
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	clone := *l
+	if l.name == "" {
+		clone.name = name
+	} else {
+		clone.name = l.name + "." + name
+	}
+	if clone.levelRegistry != nil {
+		clone.namedLevel = newNamedLevel(clone.levelRegistry, clone.name, clone.level)
+	}
+	return &clone
+}
+
+// WithLevelRegistry returns a new Logger that resolves per-name level
+// overrides from the given registry. A nil registry gives the logger an
+// empty registry of its own.
+func (l *Logger) WithLevelRegistry(registry *LevelRegistry) *Logger {
+	if registry == nil {
+		registry = NewLevelRegistry()
+	}
+	clone := *l
+	clone.levelRegistry = registry
+	clone.namedLevel = nil
+	if clone.name != "" {
+		clone.namedLevel = newNamedLevel(registry, clone.name, clone.level)
+	}
+	return &clone
+}
+
+// Name returns the logger's dotted name.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// WithFields returns a child Logger that adds the given fields to every
+// entry it writes. The fields are encoded once by the formatter rather
+// than on every log call, and are written before the entry's own fields.
+// Children of the returned Logger inherit its fields.
+func (l *Logger) WithFields(fields ...Field) *Logger {
+	clone := *l
+	if len(fields) > 0 {
+		if clone.redactor != nil {
+			fields = clone.redactor.redactFields(fields)
+		}
+		// Force a copy so siblings never share the backing array
+		clone.boundFields = make([]Field, 0, len(l.boundFields)+len(fields))
+		clone.boundFields = append(clone.boundFields, l.boundFields...)
+		clone.boundFields = append(clone.boundFields, fields...)
+		clone.encodeBoundFields()
+		if clone.slogHandler != nil {
+			clone.slogHandler = clone.slogHandler.WithAttrs(slogAttrsFromFields(fields))
+		}
+		if clone.core != nil {
+			clone.core = clone.core.With(fields)
+		}
+	}
+	return &clone
+}
+
+// WithCore returns a new Logger that hands its entries to the given Core
+// instead of formatting them and writing them to its writer. Fields already
+// bound to the logger are added to the core with With. The logger's level is
+// checked before the core's, so it must be at least as verbose as the most
+// verbose level the core accepts. The core writes entries synchronously: the
+// async buffer of the logger only serves its own writer.
+func (l *Logger) WithCore(core Core) *Logger {
+	clone := *l
+	clone.core = core
+	if core != nil && len(l.boundFields) > 0 {
+		clone.core = core.With(l.boundFields)
+	}
+	return &clone
+}
+
+// WithSlogHandler returns a new Logger that forwards its entries to the given
+// slog.Handler instead of formatting them and writing them to its writer.
+// Fields already bound to the logger are added to the handler with WithAttrs.
+func (l *Logger) WithSlogHandler(handler slog.Handler) *Logger {
+	clone := *l
+	clone.slogHandler = handler
+	if handler != nil && len(l.boundFields) > 0 {
+		clone.slogHandler = handler.WithAttrs(slogAttrsFromFields(l.boundFields))
+	}
+	return &clone
+}
+
+// encodeBoundFields pre-encodes the bound fields with the current formatter.
+func (l *Logger) encodeBoundFields() {
+	l.boundEncoded = nil
+	if len(l.boundFields) == 0 || !preEncodable(l.boundFields) {
+		return
+	}
+	encoder, ok := l.formatter.(FieldEncoder)
+	if !ok || keyPoliciesActive(l.formatter) {
+		return
+	}
+
+	buf := GetBuffer(256)
+	encoder.EncodeFields(buf, l.boundFields)
+	l.boundEncoded = append([]byte{}, buf.Bytes()...)
+	PutBuffer(buf)
+}
+
 // With returns a new Entry with the given fields.
 func (l *Logger) With(fields ...Field) *Entry {
 	e := l.newEntry()
@@ -200,7 +403,7 @@ func (l *Logger) WithContext(ctx context.Context) *Entry {
 
 // Trace logs a message at the trace level.
 func (l *Logger) Trace(msg string, fields ...Field) {
-	if !l.level.Enabled(TraceLevel) {
+	if !l.enabled(TraceLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -212,7 +415,7 @@ func (l *Logger) Trace(msg string, fields ...Field) {
 
 // Debug logs a message at the debug level.
 func (l *Logger) Debug(msg string, fields ...Field) {
-	if !l.level.Enabled(DebugLevel) {
+	if !l.enabled(DebugLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -224,7 +427,7 @@ func (l *Logger) Debug(msg string, fields ...Field) {
 
 // Info logs a message at the info level.
 func (l *Logger) Info(msg string, fields ...Field) {
-	if !l.level.Enabled(InfoLevel) {
+	if !l.enabled(InfoLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -236,7 +439,7 @@ func (l *Logger) Info(msg string, fields ...Field) {
 
 // Warn logs a message at the warn level.
 func (l *Logger) Warn(msg string, fields ...Field) {
-	if !l.level.Enabled(WarnLevel) {
+	if !l.enabled(WarnLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -248,7 +451,7 @@ func (l *Logger) Warn(msg string, fields ...Field) {
 
 // Error logs a message at the error level.
 func (l *Logger) Error(msg string, fields ...Field) {
-	if !l.level.Enabled(ErrorLevel) {
+	if !l.enabled(ErrorLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -258,9 +461,35 @@ func (l *Logger) Error(msg string, fields ...Field) {
 	e.Error(msg)
 }
 
-// Fatal logs a message at the fatal level and calls os.Exit(1).
+// DPanic logs a message at the dpanic level. In development, the logger
+// then panics.
+func (l *Logger) DPanic(msg string, fields ...Field) {
+	if !l.enabled(DPanicLevel) {
+		return
+	}
+	e := l.newEntry()
+	if len(fields) > 0 {
+		e.WithFields(fields)
+	}
+	e.DPanic(msg)
+}
+
+// Panic logs a message at the panic level, flushes the logger and panics.
+func (l *Logger) Panic(msg string, fields ...Field) {
+	if !l.enabled(PanicLevel) {
+		return
+	}
+	e := l.newEntry()
+	if len(fields) > 0 {
+		e.WithFields(fields)
+	}
+	e.Panic(msg)
+}
+
+// Fatal logs a message at the fatal level, flushes the logger and runs the
+// configured FatalAction, which calls os.Exit(1) by default.
 func (l *Logger) Fatal(msg string, fields ...Field) {
-	if !l.level.Enabled(FatalLevel) {
+	if !l.enabled(FatalLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -270,9 +499,23 @@ func (l *Logger) Fatal(msg string, fields ...Field) {
 	e.Fatal(msg)
 }
 
+// Log logs a message at the given level, which may be a custom level
+// registered with RegisterLevel. The dpanic, panic and fatal levels keep
+// their panicking and exiting behavior.
+func (l *Logger) Log(level Level, msg string, fields ...Field) {
+	if level == Disabled || !l.enabled(level) {
+		return
+	}
+	e := l.newEntry()
+	if len(fields) > 0 {
+		e.WithFields(fields)
+	}
+	e.Log(level, msg)
+}
+
 // Tracef logs a formatted message at the trace level.
 func (l *Logger) Tracef(format string, args ...interface{}) {
-	if !l.level.Enabled(TraceLevel) {
+	if !l.enabled(TraceLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -281,7 +524,7 @@ func (l *Logger) Tracef(format string, args ...interface{}) {
 
 // Debugf logs a formatted message at the debug level.
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	if !l.level.Enabled(DebugLevel) {
+	if !l.enabled(DebugLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -290,7 +533,7 @@ func (l *Logger) Debugf(format string, args ...interface{}) {
 
 // Infof logs a formatted message at the info level.
 func (l *Logger) Infof(format string, args ...interface{}) {
-	if !l.level.Enabled(InfoLevel) {
+	if !l.enabled(InfoLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -299,7 +542,7 @@ func (l *Logger) Infof(format string, args ...interface{}) {
 
 // Warnf logs a formatted message at the warn level.
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	if !l.level.Enabled(WarnLevel) {
+	if !l.enabled(WarnLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -308,16 +551,36 @@ func (l *Logger) Warnf(format string, args ...interface{}) {
 
 // Errorf logs a formatted message at the error level.
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	if !l.level.Enabled(ErrorLevel) {
+	if !l.enabled(ErrorLevel) {
 		return
 	}
 	e := l.newEntry()
 	e.Errorf(format, args...)
 }
 
-// Fatalf logs a formatted message at the fatal level and calls os.Exit(1).
+// DPanicf logs a formatted message at the dpanic level. In development, the
+// logger then panics.
+func (l *Logger) DPanicf(format string, args ...interface{}) {
+	if !l.enabled(DPanicLevel) {
+		return
+	}
+	e := l.newEntry()
+	e.DPanicf(format, args...)
+}
+
+// Panicf logs a formatted message at the panic level, flushes the logger and panics.
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	if !l.enabled(PanicLevel) {
+		return
+	}
+	e := l.newEntry()
+	e.Panicf(format, args...)
+}
+
+// Fatalf logs a formatted message at the fatal level, flushes the logger and
+// runs the configured FatalAction, which calls os.Exit(1) by default.
 func (l *Logger) Fatalf(format string, args ...interface{}) {
-	if !l.level.Enabled(FatalLevel) {
+	if !l.enabled(FatalLevel) {
 		return
 	}
 	e := l.newEntry()
@@ -329,22 +592,107 @@ func (l *Logger) Writer(level Level) io.Writer {
 	return l.newEntry().Writer(level)
 }
 
-// Close closes the logger, flushing any buffered log entries.
+// Close closes the logger, flushing any buffered log entries. It is
+// Shutdown without a deadline.
 func (l *Logger) Close() error {
+	return l.Shutdown(context.Background())
+}
+
+// Sync writes out every buffered log entry and syncs the writer, or the
+// core, if it supports it. Writers that cannot be synced, such as os.Stdout
+// when it is a pipe or terminal, are not reported as errors.
+func (l *Logger) Sync() error {
 	if l.EnableAsync && l.asyncBuffer != nil {
-		return l.asyncBuffer.close()
+		l.asyncBuffer.drain()
 	}
-	return nil
+	if l.core != nil {
+		return l.core.Sync()
+	}
+	return syncWriter(l.writer)
 }
 
-// SetLevel sets the logger's level.
+// Shutdown closes the logger. It writes out buffered log entries until they
+// are all written or ctx is done, stops the async worker and syncs the
+// writer. Entries still buffered when ctx is done are discarded, and a
+// *ShutdownError reports how many were lost. The deadline is checked between
+// batches, so a writer that blocks can delay Shutdown past it.
+//
+// The logger and every logger derived from it are closed: later log calls
+// pass ErrLoggerClosed to the error handler. Calling Shutdown again does nothing.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	if l.state != nil && !l.state.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	var lost int64
+	if l.EnableAsync && l.asyncBuffer != nil {
+		for l.asyncBuffer.pending() > 0 && ctx.Err() == nil {
+			l.asyncBuffer.flush()
+		}
+		if ctx.Err() != nil {
+			lost = l.asyncBuffer.discard()
+		}
+		l.asyncBuffer.close()
+	}
+
+	err := l.Sync()
+	if lost > 0 {
+		return errors.Join(&ShutdownError{Lost: lost, Err: ctx.Err()}, err)
+	}
+	return err
+}
+
+// closed returns whether the logger has been shut down.
+func (l *Logger) closed() bool {
+	return l.state != nil && l.state.closed.Load()
+}
+
+// SetLevel sets the logger's level. For a named logger, this sets the
+// per-name override of its exact name, creating it if needed, so that its
+// parent, its siblings and the loggers sharing a prefix keep their level.
 func (l *Logger) SetLevel(level Level) {
+	if l.namedLevel != nil {
+		l.namedLevel.registry.SetLevel(l.name, level)
+		return
+	}
 	l.level.SetLevel(level)
 }
 
 // GetLevel returns the logger's level.
 func (l *Logger) GetLevel() Level {
-	return l.level.Level()
+	return l.levelHandle().Level()
+}
+
+// levelHandle returns the AtomicLevel in effect for the logger.
+func (l *Logger) levelHandle() *AtomicLevel {
+	if l.namedLevel != nil {
+		return l.namedLevel.resolve()
+	}
+	return l.level
+}
+
+// enabled returns whether the given level is enabled for the logger.
+func (l *Logger) enabled(level Level) bool {
+	if !l.levelHandle().Enabled(level) {
+		return false
+	}
+	return l.core == nil || l.core.Enabled(level)
+}
+
+// flush syncs the logger, passing any error to the error handler.
+func (l *Logger) flush() {
+	if err := l.Sync(); err != nil && l.errorHandler != nil {
+		l.errorHandler(err)
+	}
+}
+
+// fatal runs the configured FatalAction, exiting with status 1 by default.
+func (l *Logger) fatal(msg string) {
+	if l.onFatal != nil {
+		l.onFatal(msg)
+		return
+	}
+	exit(1)
 }
 
 // writeAsync writes the given bytes to the async buffer.
@@ -362,26 +710,25 @@ func (l *Logger) writeAsync(p []byte) {
 	}
 }
 
-// getCaller returns the file and line number of the caller.
+// getCaller returns the file and line number of the first caller outside
+// this package, after skipping skip more frames.
 func getCaller(skip int) *CallerInfo {
-	pc, file, line, ok := runtime.Caller(skip + 1)
-	if !ok {
+	var info *CallerInfo
+	walkCallers(skip, func(frame runtime.Frame) bool {
+		info = &CallerInfo{
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+			pc:       frame.PC + 1,
+		}
+		return false
+	})
+	if info == nil {
 		return &CallerInfo{
 			File:     "unknown",
 			Line:     0,
 			Function: "unknown",
 		}
 	}
-
-	fn := runtime.FuncForPC(pc)
-	funcName := "unknown"
-	if fn != nil {
-		funcName = fn.Name()
-	}
-
-	return &CallerInfo{
-		File:     file,
-		Line:     line,
-		Function: funcName,
-	}
-}
\ No newline at end of file
+	return info
+}
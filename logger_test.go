@@ -0,0 +1,126 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestZeroValueConfig(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&Config{Writer: &buf})
+	logger.Info("hi", Str("password", "hunter2"))
+
+	out := buf.String()
+	if !strings.Contains(out, "hi") || !strings.Contains(out, "hunter2") {
+		t.Errorf("zero-value Config output = %q", out)
+	}
+	if strings.Contains(out, "stacktrace") || strings.Contains(out, "caller") {
+		t.Errorf("zero-value Config enabled stack traces or callers: %q", out)
+	}
+}
+
+func TestZeroValueConfigHasNoStacktrace(t *testing.T) {
+	var buf bytes.Buffer
+	New(&Config{Writer: &buf, Formatter: NewJSONFormatter()}).Info("hi")
+	if strings.Contains(buf.String(), "stacktrace") {
+		t.Errorf("zero-value Config attached a stack trace: %s", buf.String())
+	}
+
+	buf.Reset()
+	New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter()), WithStacktraceLevel(ErrorLevel))).Error("boom")
+	if !strings.Contains(buf.String(), `"stacktrace":[`) {
+		t.Errorf("WithStacktraceLevel(ErrorLevel) attached no stack trace: %s", buf.String())
+	}
+}
+
+func TestCloseIgnoresUnsyncableWriter(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if err := New(NewConfig(WithWriter(w))).Close(); err != nil {
+		t.Errorf("Close with a pipe writer = %v, want nil", err)
+	}
+}
+
+// countingWriter counts the lines written to it.
+type countingWriter struct {
+	mu    sync.Mutex
+	lines int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
+
+func TestShutdownAccountsForLostEntries(t *testing.T) {
+	w := &countingWriter{}
+	logger := New(NewConfig(WithWriter(w), WithAsync(true), WithFlushInterval(time.Hour)))
+	for i := 0; i < 10; i++ {
+		logger.Info("queued")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := logger.Shutdown(ctx)
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("Shutdown = %v, want a *ShutdownError", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Shutdown error %v does not wrap context.Canceled", err)
+	}
+	if got := shutdownErr.Lost + int64(w.lines); shutdownErr.Lost == 0 || got != 10 {
+		t.Errorf("lost %d and wrote %d entries, want 10 in total with some lost", shutdownErr.Lost, w.lines)
+	}
+
+	if err := logger.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown = %v, want nil", err)
+	}
+}
+
+func TestShutdownWritesEverythingWithoutDeadline(t *testing.T) {
+	w := &countingWriter{}
+	logger := New(NewConfig(WithWriter(w), WithAsync(true), WithFlushInterval(time.Hour)))
+	for i := 0; i < 10; i++ {
+		logger.Info("queued")
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close = %v", err)
+	}
+	if w.lines != 10 {
+		t.Errorf("wrote %d entries, want 10", w.lines)
+	}
+}
+
+func TestClosedLoggerRefusesEntries(t *testing.T) {
+	var buf bytes.Buffer
+	var handled []error
+	logger := New(NewConfig(WithWriter(&buf), WithErrorHandler(func(err error) {
+		handled = append(handled, err)
+	})))
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close = %v", err)
+	}
+
+	logger.Info("after close")
+	if buf.Len() != 0 {
+		t.Errorf("closed logger wrote %q", buf.String())
+	}
+	if len(handled) != 1 || !errors.Is(handled[0], ErrLoggerClosed) {
+		t.Errorf("error handler got %v, want ErrLoggerClosed", handled)
+	}
+}
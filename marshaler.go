@@ -0,0 +1,90 @@
+package onelog
+
+import (
+	"time"
+)
+
+// ObjectMarshaler is implemented by types that can log themselves as a
+// structured object, without reflection.
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ArrayMarshaler is implemented by types that can log themselves as an
+// array, without reflection.
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ObjectMarshalerFunc is a function that implements ObjectMarshaler.
+type ObjectMarshalerFunc func(enc ObjectEncoder) error
+
+// MarshalLogObject implements ObjectMarshaler.
+func (f ObjectMarshalerFunc) MarshalLogObject(enc ObjectEncoder) error {
+	return f(enc)
+}
+
+// ArrayMarshalerFunc is a function that implements ArrayMarshaler.
+type ArrayMarshalerFunc func(enc ArrayEncoder) error
+
+// MarshalLogArray implements ArrayMarshaler.
+func (f ArrayMarshalerFunc) MarshalLogArray(enc ArrayEncoder) error {
+	return f(enc)
+}
+
+// ObjectEncoder adds the members of an object. Each formatter provides its
+// own: JSON writes nested objects, logfmt writes dotted keys and text writes
+// {key=value} groups.
+type ObjectEncoder interface {
+	AddString(key, value string)
+	AddBool(key string, value bool)
+	AddInt(key string, value int)
+	AddInt64(key string, value int64)
+	AddUint64(key string, value uint64)
+	AddFloat64(key string, value float64)
+	AddTime(key string, value time.Time)
+	AddDuration(key string, value time.Duration)
+	AddObject(key string, value ObjectMarshaler) error
+	AddArray(key string, value ArrayMarshaler) error
+}
+
+// ArrayEncoder appends the elements of an array.
+type ArrayEncoder interface {
+	AppendString(value string)
+	AppendBool(value bool)
+	AppendInt(value int)
+	AppendInt64(value int64)
+	AppendUint64(value uint64)
+	AppendFloat64(value float64)
+	AppendTime(value time.Time)
+	AppendDuration(value time.Duration)
+	AppendObject(value ObjectMarshaler) error
+	AppendArray(value ArrayMarshaler) error
+}
+
+// Object creates a Field with a value that marshals itself as an object.
+// Array and Any also accept values that implement ArrayMarshaler or
+// ObjectMarshaler.
+func Object(key string, val ObjectMarshaler) Field {
+	return Field{
+		Key:       key,
+		Type:      ObjectType,
+		Interface: val,
+	}
+}
+
+// marshalers returns the field's value as an ObjectMarshaler or an
+// ArrayMarshaler, if it implements one. Structs, maps, slices and arrays
+// that do not are walked by reflection.
+func marshalers(field Field, opts *FormatterOptions) (ObjectMarshaler, ArrayMarshaler) {
+	if field.Type != ObjectType && field.Type != ArrayType {
+		return nil, nil
+	}
+	switch value := field.Interface.(type) {
+	case ObjectMarshaler:
+		return value, nil
+	case ArrayMarshaler:
+		return nil, value
+	}
+	return reflectMarshalers(field.Interface, opts)
+}
@@ -0,0 +1,207 @@
+package onelog
+
+import (
+	"path"
+	"strings"
+)
+
+// redactor marks the fields whose keys match sensitive key patterns. Each
+// logger built with Config.RedactSensitiveFields holds its own, compiled from
+// SensitiveKeys and Config.AdditionalSensitiveKeys when the logger is created.
+type redactor struct {
+	// sensitive matches the keys to redact.
+	sensitive keyMatcher
+	// exempt matches the keys never redacted, from patterns starting with "!".
+	exempt keyMatcher
+}
+
+// keyMatcher matches lowercase keys against compiled key patterns.
+type keyMatcher struct {
+	exact    map[string]struct{}
+	suffixes []string
+	contains []string
+	globs    []string
+}
+
+// globChars are the metacharacters of path.Match.
+const globChars = "*?[\\"
+
+// newRedactor compiles the patterns of each list, lowercased, into a
+// redactor. It returns nil if no pattern redacts anything.
+func newRedactor(lists ...[]string) *redactor {
+	r := &redactor{}
+	for _, list := range lists {
+		for _, pattern := range list {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if rest, ok := strings.CutPrefix(pattern, "!"); ok {
+				r.exempt.add(rest)
+			} else {
+				r.sensitive.add(pattern)
+			}
+		}
+	}
+	if r.sensitive.empty() {
+		return nil
+	}
+	return r
+}
+
+// add compiles a lowercase pattern into m.
+func (m *keyMatcher) add(pattern string) {
+	switch {
+	case pattern == "":
+	case isContainsPattern(pattern):
+		m.contains = append(m.contains, pattern[1:len(pattern)-1])
+	case isSuffixPattern(pattern):
+		m.suffixes = append(m.suffixes, pattern[1:])
+	case isGlobPattern(pattern):
+		m.globs = append(m.globs, pattern)
+	default:
+		if m.exact == nil {
+			m.exact = make(map[string]struct{})
+		}
+		m.exact[pattern] = struct{}{}
+	}
+}
+
+// empty returns whether m matches no key.
+func (m *keyMatcher) empty() bool {
+	return len(m.exact) == 0 && len(m.suffixes) == 0 && len(m.contains) == 0 && len(m.globs) == 0
+}
+
+// match returns whether the lowercase key matches one of the patterns of m.
+func (m *keyMatcher) match(key string) bool {
+	if _, ok := m.exact[key]; ok {
+		return true
+	}
+	for _, suffix := range m.suffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	for _, sub := range m.contains {
+		if strings.Contains(key, sub) {
+			return true
+		}
+	}
+	for _, glob := range m.globs {
+		if matched, _ := path.Match(glob, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isLiteral returns whether s has no glob metacharacter.
+func isLiteral(s string) bool {
+	return !strings.ContainsAny(s, globChars)
+}
+
+// isSuffixPattern returns whether pattern is "*" followed by a literal.
+func isSuffixPattern(pattern string) bool {
+	return len(pattern) > 1 && pattern[0] == '*' && isLiteral(pattern[1:])
+}
+
+// isContainsPattern returns whether pattern is a literal between two "*".
+func isContainsPattern(pattern string) bool {
+	return len(pattern) > 2 && pattern[0] == '*' && pattern[len(pattern)-1] == '*' &&
+		isLiteral(pattern[1:len(pattern)-1])
+}
+
+// isGlobPattern returns whether pattern is a valid path.Match pattern with
+// metacharacters. An invalid pattern is matched literally.
+func isGlobPattern(pattern string) bool {
+	if isLiteral(pattern) {
+		return false
+	}
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+// matchKeyPattern returns whether the lowercase key matches the lowercase
+// pattern, which must not be an exemption.
+func matchKeyPattern(pattern, key string) bool {
+	switch {
+	case pattern == "":
+		return false
+	case isContainsPattern(pattern):
+		return strings.Contains(key, pattern[1:len(pattern)-1])
+	case isSuffixPattern(pattern):
+		return strings.HasSuffix(key, pattern[1:])
+	case isGlobPattern(pattern):
+		matched, _ := path.Match(pattern, key)
+		return matched
+	}
+	return pattern == key
+}
+
+// match returns whether key is sensitive.
+func (r *redactor) match(key string) bool {
+	key = fastLowerCase(key)
+	return r.sensitive.match(key) && !r.exempt.match(key)
+}
+
+// redactField returns field marked as sensitive if its key matches, or with
+// the matching members of a Dict marked, and whether anything changed. The
+// members of a Dict are copied rather than modified.
+func (r *redactor) redactField(field Field) (Field, bool) {
+	if field.IsSensitive {
+		return field, false
+	}
+	if r.match(field.Key) {
+		field.IsSensitive = true
+		return field, true
+	}
+	group, ok := field.Interface.(dict)
+	if !ok || field.Type != ObjectType {
+		return field, false
+	}
+	var redacted dict
+	for i := range group {
+		member, changed := r.redactField(group[i])
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(dict, len(group))
+			copy(redacted, group)
+		}
+		redacted[i] = member
+	}
+	if redacted == nil {
+		return field, false
+	}
+	field.Interface = redacted
+	return field, true
+}
+
+// redactFields returns fields with the sensitive ones marked. fields is not
+// modified; a new slice is only allocated if a field is redacted.
+func (r *redactor) redactFields(fields []Field) []Field {
+	var redacted []Field
+	for i := range fields {
+		field, changed := r.redactField(fields[i])
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i] = field
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactFields marks the sensitive fields of the entry in place.
+func (e *Entry) redactFields() {
+	r := e.logger.redactor
+	for i := range e.fields {
+		if field, changed := r.redactField(e.fields[i]); changed {
+			e.fields[i] = field
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package onelog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		// Redacted by the original substring matcher
+		{"password", true},
+		{"db_password", true},
+		{"Password_Hash", true},
+		{"passwd", true},
+		{"secret", true},
+		{"client_secret", true},
+		{"secret_key", true},
+		{"aws_secret_access_key", true},
+		{"token", true},
+		{"access_token", true},
+		{"refresh_token", true},
+		{"accessToken", true},
+		{"auth", true},
+		{"auth_header", true},
+		{"basic_auth", true},
+		{"authorization", true},
+		{"credential", true},
+		{"credentials", true},
+		{"api_key", true},
+		{"x-api-key", true},
+		{"apikey", true},
+		{"private_key", true},
+		{"private_key_pem", true},
+		{"privatekey", true},
+		{"ssh_key", true},
+		{"encryption_key", true},
+		{"signing-key", true},
+
+		// False positives of the original substring matcher
+		{"cache_key", false},
+		{"monkey", false},
+		{"keyboard", false},
+		{"key", false},
+		{"author", false},
+		{"authority", false},
+		{"idempotency_key", false},
+		{"partition_key", false},
+		{"max_tokens", false},
+		{"total_tokens", false},
+		{"user_id", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsSensitiveKey(tt.key); got != tt.want {
+			t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+		r := newRedactor(SensitiveKeys)
+		if got := r.match(tt.key); got != tt.want {
+			t.Errorf("redactor.match(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedactorPatterns(t *testing.T) {
+	r := newRedactor([]string{"SSN", "*_pin", "card_*", "*iban*", "user_?", "!card_type"})
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"ssn", true},
+		{"SSN", true},
+		{"ssn_last4", false},
+		{"atm_pin", true},
+		{"pin_code", false},
+		{"card_number", true},
+		{"card_type", false},
+		{"customer_iban_hash", true},
+		{"user_1", true},
+		{"user_10", false},
+	}
+	for _, tt := range tests {
+		if got := r.match(tt.key); got != tt.want {
+			t.Errorf("match(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	if newRedactor(nil, []string{"", "!password"}) != nil {
+		t.Error("newRedactor with only exemptions should be nil")
+	}
+}
+
+func TestRedactFieldsDoesNotModifyInput(t *testing.T) {
+	r := newRedactor(SensitiveKeys)
+	fields := []Field{Str("user", "ann"), Dict("db", Str("host", "h"), Str("password", "p"))}
+
+	redacted := r.redactFields(fields)
+	if fields[1].Interface.(dict)[1].IsSensitive {
+		t.Fatal("redactFields modified the members of the input Dict")
+	}
+	if redacted[0].IsSensitive || !redacted[1].Interface.(dict)[1].IsSensitive {
+		t.Fatalf("redactFields = %+v", redacted)
+	}
+
+	clean := []Field{Str("user", "ann")}
+	if got := r.redactFields(clean); &got[0] != &clean[0] {
+		t.Error("redactFields copied fields without sensitive keys")
+	}
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(
+		WithWriter(&buf),
+		WithFormatter(NewJSONFormatter()),
+		WithAdditionalSensitiveKeys("ssn"),
+	))
+	logger.WithFields(Str("db_password", "hunter2")).Info("login",
+		Str("ssn", "123-45-6789"), Str("cache_key", "user:1"))
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "123-45-6789"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("output contains %q: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, `"cache_key":"user:1"`) {
+		t.Errorf("cache_key was redacted: %s", out)
+	}
+
+	buf.Reset()
+	New(NewConfig(WithWriter(&buf), WithRedactSensitiveFields(false))).Info("login", Str("password", "hunter2"))
+	if !strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("redaction disabled but password redacted: %s", buf.String())
+	}
+}
@@ -0,0 +1,455 @@
+package onelog
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxDepth is the depth limit used when FormatterOptions.MaxDepth
+	// is not set.
+	defaultMaxDepth = 10
+	// maxDepthValue replaces values nested deeper than the depth limit.
+	maxDepthValue = "<max depth>"
+	// cycleValue replaces pointers and maps that refer back to a value that
+	// is being encoded.
+	cycleValue = "<cycle>"
+	// nilValue is written for nil pointers, maps, slices and interfaces.
+	nilValue = "<nil>"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	objectMarshalerType = reflect.TypeOf((*ObjectMarshaler)(nil)).Elem()
+	arrayMarshalerType  = reflect.TypeOf((*ArrayMarshaler)(nil)).Elem()
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	logValuerType       = reflect.TypeOf((*slog.LogValuer)(nil)).Elem()
+)
+
+// structPlan lists the fields of a struct type that are encoded, with the
+// options of their log tags.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+// fieldPlan describes how a struct field is encoded.
+type fieldPlan struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	redact    bool
+}
+
+// structPlans caches the plan of each struct type.
+var structPlans sync.Map // map[reflect.Type]*structPlan
+
+// planFor returns the cached plan of a struct type, building it on first use.
+func planFor(t reflect.Type) *structPlan {
+	if plan, ok := structPlans.Load(t); ok {
+		return plan.(*structPlan)
+	}
+
+	plan := &structPlan{}
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() {
+			continue
+		}
+		tag, tagged := sf.Tag.Lookup("log")
+		if tag == "-" {
+			continue
+		}
+		// The fields of untagged embedded structs are promoted, so the
+		// embedded struct itself is skipped.
+		if sf.Anonymous && !tagged && indirectType(sf.Type).Kind() == reflect.Struct {
+			continue
+		}
+
+		field := fieldPlan{
+			index: sf.Index,
+			name:  sf.Name,
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name != "" {
+			field.name = name
+		}
+		for options != "" {
+			var option string
+			option, options, _ = strings.Cut(options, ",")
+			switch option {
+			case "omitempty":
+				field.omitEmpty = true
+			case "redact":
+				field.redact = true
+			}
+		}
+		plan.fields = append(plan.fields, field)
+	}
+
+	actual, _ := structPlans.LoadOrStore(t, plan)
+	return actual.(*structPlan)
+}
+
+// indirectType returns the type a pointer type points to, or t itself.
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// reflectWalker holds the state of encoding one value by reflection.
+type reflectWalker struct {
+	opts     *FormatterOptions
+	maxDepth int
+	depth    int
+	// seen holds the pointers and maps on the path to the value being
+	// encoded, to detect cycles.
+	seen []uintptr
+}
+
+// reflectedValue is a value encoded by reflection. It marshals structs and
+// maps as objects, and slices and arrays as arrays.
+type reflectedValue struct {
+	walker *reflectWalker
+	value  reflect.Value
+}
+
+// reflectMarshalers returns val as a reflection-based ObjectMarshaler or
+// ArrayMarshaler, if it is a struct, map, slice or array, or a pointer to one.
+func reflectMarshalers(val interface{}, opts *FormatterOptions) (ObjectMarshaler, ArrayMarshaler) {
+	if val == nil {
+		return nil, nil
+	}
+	v := reflect.ValueOf(val)
+	if hasFormatting(v.Type()) {
+		return nil, nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() || hasFormatting(v.Type().Elem()) {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	walker := &reflectWalker{opts: opts, maxDepth: maxDepth}
+	if ptr := reflect.ValueOf(val); ptr.Kind() == reflect.Pointer || ptr.Kind() == reflect.Map {
+		walker.seen = append(walker.seen, ptr.Pointer())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return reflectedValue{walker, v}, nil
+	case reflect.Map:
+		if !v.IsNil() {
+			return reflectedValue{walker, v}, nil
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() != reflect.Uint8 && (v.Kind() == reflect.Array || !v.IsNil()) {
+			return nil, reflectedValue{walker, v}
+		}
+	}
+	return nil, nil
+}
+
+// hasFormatting returns whether values of type t format themselves, as times,
+// durations, errors, Stringers, JSON and text marshalers and slog.LogValuers
+// do, and so are not walked.
+func hasFormatting(t reflect.Type) bool {
+	return t == timeType || t == durationType || t.Implements(errorType) || t.Implements(stringerType) ||
+		t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) || t.Implements(logValuerType)
+}
+
+// MarshalLogObject implements ObjectMarshaler for structs and maps.
+func (r reflectedValue) MarshalLogObject(enc ObjectEncoder) error {
+	w := r.walker
+	w.depth++
+	defer func() { w.depth-- }()
+
+	if r.value.Kind() == reflect.Map {
+		return w.encodeMap(enc, r.value)
+	}
+
+	plan := planFor(r.value.Type())
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		fv, err := r.value.FieldByIndexErr(field.index)
+		if err != nil {
+			// The field is promoted through a nil embedded pointer.
+			continue
+		}
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		if field.redact {
+			enc.AddString(field.name, w.opts.RedactedValue)
+			continue
+		}
+		if err := w.addValue(enc, field.name, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalLogArray implements ArrayMarshaler for slices and arrays.
+func (r reflectedValue) MarshalLogArray(enc ArrayEncoder) error {
+	w := r.walker
+	w.depth++
+	defer func() { w.depth-- }()
+
+	elements := elementEncoder{enc}
+	for i := 0; i < r.value.Len(); i++ {
+		if err := w.addValue(elements, "", r.value.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMap adds the entries of a map, sorted by key.
+func (w *reflectWalker) encodeMap(enc ObjectEncoder, m reflect.Value) error {
+	keys := m.MapKeys()
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = mapKeyString(key)
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return names[order[i]] < names[order[j]]
+	})
+
+	for _, i := range order {
+		if err := w.addValue(enc, names[i], m.MapIndex(keys[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapKeyString returns the string form of a map key.
+func mapKeyString(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10)
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// addValue adds a value to an object under key, walking it if it is a
+// struct, map, slice or array.
+func (w *reflectWalker) addValue(enc ObjectEncoder, key string, v reflect.Value) error {
+	// Unwrap interfaces and pointers, checking pointers for cycles
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			enc.AddString(key, nilValue)
+			return nil
+		}
+		if v.Kind() == reflect.Pointer {
+			if v.Type().Implements(objectMarshalerType) || v.Type().Implements(arrayMarshalerType) || hasFormatting(v.Type()) {
+				break
+			}
+			if w.visiting(v.Pointer()) {
+				enc.AddString(key, cycleValue)
+				return nil
+			}
+			w.seen = append(w.seen, v.Pointer())
+			defer func() { w.seen = w.seen[:len(w.seen)-1] }()
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		enc.AddString(key, nilValue)
+		return nil
+	}
+
+	// Values that encode or format themselves
+	if v.CanInterface() {
+		t := v.Type()
+		switch {
+		case t == timeType:
+			enc.AddTime(key, v.Interface().(time.Time))
+			return nil
+		case t == durationType:
+			enc.AddDuration(key, time.Duration(v.Int()))
+			return nil
+		case t.Implements(objectMarshalerType):
+			return w.nested(enc, key, func() error {
+				return enc.AddObject(key, v.Interface().(ObjectMarshaler))
+			})
+		case t.Implements(arrayMarshalerType):
+			return w.nested(enc, key, func() error {
+				return enc.AddArray(key, v.Interface().(ArrayMarshaler))
+			})
+		case t.Implements(logValuerType):
+			value := v.Interface().(slog.LogValuer).LogValue().Resolve()
+			return addField(enc, logValueField(key, value), w.opts)
+		case t.Implements(textMarshalerType), t.Implements(errorType), t.Implements(stringerType):
+			text, _ := textOf(v.Interface())
+			enc.AddString(key, text)
+			return nil
+		case t.Implements(jsonMarshalerType):
+			enc.AddString(key, jsonText(v.Interface().(json.Marshaler)))
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		enc.AddBool(key, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		enc.AddInt64(key, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		enc.AddUint64(key, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		enc.AddFloat64(key, v.Float())
+	case reflect.String:
+		enc.AddString(key, v.String())
+	case reflect.Struct:
+		return w.nested(enc, key, func() error {
+			return enc.AddObject(key, reflectedValue{w, v})
+		})
+	case reflect.Map:
+		if v.IsNil() {
+			enc.AddString(key, nilValue)
+			return nil
+		}
+		if w.visiting(v.Pointer()) {
+			enc.AddString(key, cycleValue)
+			return nil
+		}
+		w.seen = append(w.seen, v.Pointer())
+		defer func() { w.seen = w.seen[:len(w.seen)-1] }()
+		return w.nested(enc, key, func() error {
+			return enc.AddObject(key, reflectedValue{w, v})
+		})
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			enc.AddString(key, nilValue)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			enc.AddString(key, base64.StdEncoding.EncodeToString(byteSlice(v)))
+			return nil
+		}
+		return w.nested(enc, key, func() error {
+			return enc.AddArray(key, reflectedValue{w, v})
+		})
+	default:
+		if v.CanInterface() {
+			enc.AddString(key, fmt.Sprint(v.Interface()))
+		} else {
+			enc.AddString(key, v.Type().String())
+		}
+	}
+	return nil
+}
+
+// nested adds a nested object or array with add, or maxDepthValue if the
+// depth limit has been reached.
+func (w *reflectWalker) nested(enc ObjectEncoder, key string, add func() error) error {
+	if w.depth >= w.maxDepth {
+		enc.AddString(key, maxDepthValue)
+		return nil
+	}
+	return add()
+}
+
+// visiting returns whether ptr is on the path to the value being encoded.
+func (w *reflectWalker) visiting(ptr uintptr) bool {
+	for _, seen := range w.seen {
+		if seen == ptr {
+			return true
+		}
+	}
+	return false
+}
+
+// byteSlice returns the bytes of a byte slice or array.
+func byteSlice(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// elementEncoder adapts an ArrayEncoder to ObjectEncoder, so array elements
+// can be added with the same code as object members. Keys are ignored.
+type elementEncoder struct {
+	enc ArrayEncoder
+}
+
+// AddString implements ObjectEncoder.
+func (e elementEncoder) AddString(_ string, value string) {
+	e.enc.AppendString(value)
+}
+
+// AddBool implements ObjectEncoder.
+func (e elementEncoder) AddBool(_ string, value bool) {
+	e.enc.AppendBool(value)
+}
+
+// AddInt implements ObjectEncoder.
+func (e elementEncoder) AddInt(_ string, value int) {
+	e.enc.AppendInt(value)
+}
+
+// AddInt64 implements ObjectEncoder.
+func (e elementEncoder) AddInt64(_ string, value int64) {
+	e.enc.AppendInt64(value)
+}
+
+// AddUint64 implements ObjectEncoder.
+func (e elementEncoder) AddUint64(_ string, value uint64) {
+	e.enc.AppendUint64(value)
+}
+
+// AddFloat64 implements ObjectEncoder.
+func (e elementEncoder) AddFloat64(_ string, value float64) {
+	e.enc.AppendFloat64(value)
+}
+
+// AddTime implements ObjectEncoder.
+func (e elementEncoder) AddTime(_ string, value time.Time) {
+	e.enc.AppendTime(value)
+}
+
+// AddDuration implements ObjectEncoder.
+func (e elementEncoder) AddDuration(_ string, value time.Duration) {
+	e.enc.AppendDuration(value)
+}
+
+// AddObject implements ObjectEncoder.
+func (e elementEncoder) AddObject(_ string, value ObjectMarshaler) error {
+	return e.enc.AppendObject(value)
+}
+
+// AddArray implements ObjectEncoder.
+func (e elementEncoder) AddArray(_ string, value ArrayMarshaler) error {
+	return e.enc.AppendArray(value)
+}
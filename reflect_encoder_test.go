@@ -0,0 +1,81 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+type testNode struct {
+	Name string
+	Next *testNode
+}
+
+type testAccount struct {
+	ID       int    `log:"id"`
+	Email    string `log:"email,omitempty"`
+	Password string `log:"password,redact"`
+	Internal string `log:"-"`
+	private  string
+}
+
+// encodeJSON writes an entry with the field using the JSON formatter and
+// returns the decoded value of the field.
+func encodeJSON(t *testing.T, field Field, maxDepth int) any {
+	t.Helper()
+	var buf bytes.Buffer
+	formatter := NewJSONFormatter()
+	formatter.Options.MaxDepth = maxDepth
+	New(NewConfig(WithWriter(&buf), WithFormatter(formatter))).Info("hi", field)
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	return out[field.Key]
+}
+
+func TestReflectCycle(t *testing.T) {
+	a := &testNode{Name: "a"}
+	b := &testNode{Name: "b", Next: a}
+	a.Next = b
+
+	got, _ := encodeJSON(t, Any("node", a), 0).(map[string]any)
+	next, _ := got["Next"].(map[string]any)
+	if got["Name"] != "a" || next["Name"] != "b" || next["Next"] != cycleValue {
+		t.Errorf("node = %v", got)
+	}
+
+	// A value seen twice without a cycle is not reported as one
+	shared := &testNode{Name: "shared"}
+	pair := []*testNode{shared, shared}
+	list, _ := encodeJSON(t, Any("pair", pair), 0).([]any)
+	if len(list) != 2 || list[1].(map[string]any)["Name"] != "shared" {
+		t.Errorf("pair = %v", list)
+	}
+}
+
+func TestReflectMaxDepth(t *testing.T) {
+	deep := map[string]any{"l1": map[string]any{"l2": map[string]any{"l3": "leaf"}}}
+
+	got, _ := encodeJSON(t, Any("deep", deep), 2).(map[string]any)
+	l1, _ := got["l1"].(map[string]any)
+	if l1["l2"] != maxDepthValue {
+		t.Errorf("deep with MaxDepth 2 = %v", got)
+	}
+
+	got, _ = encodeJSON(t, Any("deep", deep), 0).(map[string]any)
+	l1, _ = got["l1"].(map[string]any)
+	if l2, _ := l1["l2"].(map[string]any); l2["l3"] != "leaf" {
+		t.Errorf("deep with the default MaxDepth = %v", got)
+	}
+}
+
+func TestReflectStructTags(t *testing.T) {
+	account := testAccount{ID: 7, Password: "hunter2", Internal: "x", private: "y"}
+	got, _ := encodeJSON(t, Any("account", account), 0).(map[string]any)
+
+	if len(got) != 2 || got["id"] != float64(7) || got["password"] != DefaultFormatterOptions().RedactedValue {
+		t.Errorf("account = %v", got)
+	}
+}
@@ -74,51 +74,49 @@ func NewKeySampler(n int, key string) *KeySampler {
 
 // Sample implements the Sampler interface.
 func (s *KeySampler) Sample(e *Entry) bool {
-	// Find the key field.
-	for i := range e.fields {
-		field := &e.fields[i]
-		if field.Key == s.Key {
-			// Get a hash function from the pool
-			h := s.hashPool.Get().(hash.Hash32)
-			h.Reset()
-			
-			// Hash the field value.
-			switch field.Type {
-			case StringType:
-				h.Write([]byte(field.String))
-			case IntType, Int64Type:
-				var buf [8]byte
-				for i := 0; i < 8; i++ {
-					buf[i] = byte(field.Integer >> (i * 8))
-				}
-				h.Write(buf[:])
-			case UintType, Uint64Type:
-				var buf [8]byte
-				v := uint64(field.Integer)
-				for i := 0; i < 8; i++ {
-					buf[i] = byte(v >> (i * 8))
-				}
-				h.Write(buf[:])
-			case ErrorType:
-				h.Write([]byte(field.String))
-			default:
-				// Can't hash this, so sample it.
-				s.hashPool.Put(h)
-				return true
-			}
+	// Find the key field, falling back to the fields bound to the logger.
+	field := e.lookupField(s.Key)
+	if field == nil {
+		// Key not found, so sample it.
+		return true
+	}
 
-			// Check if the hash is a multiple of N.
-			result := h.Sum32()%uint32(s.N) == 0
-			
-			// Return the hash function to the pool
-			s.hashPool.Put(h)
-			
-			return result
+	// Get a hash function from the pool
+	h := s.hashPool.Get().(hash.Hash32)
+	h.Reset()
+	
+	// Hash the field value.
+	switch field.Type {
+	case StringType:
+		h.Write([]byte(field.String))
+	case IntType, Int64Type:
+		var buf [8]byte
+		for i := 0; i < 8; i++ {
+			buf[i] = byte(field.Integer >> (i * 8))
 		}
+		h.Write(buf[:])
+	case UintType, Uint64Type:
+		var buf [8]byte
+		v := uint64(field.Integer)
+		for i := 0; i < 8; i++ {
+			buf[i] = byte(v >> (i * 8))
+		}
+		h.Write(buf[:])
+	case ErrorType:
+		h.Write([]byte(field.String))
+	default:
+		// Can't hash this, so sample it.
+		s.hashPool.Put(h)
+		return true
 	}
 
-	// Key not found, so sample it.
-	return true
+	// Check if the hash is a multiple of N.
+	result := h.Sum32()%uint32(s.N) == 0
+	
+	// Return the hash function to the pool
+	s.hashPool.Put(h)
+	
+	return result
 }
 
 // AdaptiveSampler samples logs based on log volume.
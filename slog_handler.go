@@ -0,0 +1,236 @@
+package onelog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+)
+
+// SlogHandler is a slog.Handler that writes records through a Logger, so
+// they go through the logger's sampler, hooks, formatter and async buffer.
+type SlogHandler struct {
+	logger *Logger
+	// groups are the groups opened by WithGroup, outermost first.
+	groups []slogGroup
+}
+
+// slogGroup is a group opened by WithGroup, with the attributes added to it
+// by WithAttrs.
+type slogGroup struct {
+	name   string
+	fields []Field
+}
+
+// NewSlogHandler creates a new SlogHandler that writes to the given logger.
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+	}
+}
+
+// NewSlogLogger creates a new slog.Logger that writes to the given logger.
+func NewSlogLogger(logger *Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(logger))
+}
+
+// Enabled implements slog.Handler.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.enabled(LevelFromSlog(level))
+}
+
+// Handle implements slog.Handler.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := LevelFromSlog(r.Level)
+	if !h.logger.enabled(level) {
+		return nil
+	}
+
+	e := h.logger.newEntry()
+	e.level = level
+	e.message = r.Message
+	e.ctx = ctx
+	if !r.Time.IsZero() {
+		e.time = r.Time
+	}
+
+	if len(h.groups) == 0 {
+		r.Attrs(func(attr slog.Attr) bool {
+			e.fields = appendSlogAttr(e.fields, attr)
+			return true
+		})
+	} else {
+		var fields []Field
+		r.Attrs(func(attr slog.Attr) bool {
+			fields = appendSlogAttr(fields, attr)
+			return true
+		})
+		e.fields = append(e.fields, h.groupFields(fields)...)
+	}
+
+	// Use the call site recorded by slog rather than walking the stack,
+	// which would only find log/slog itself
+	if h.logger.enableCaller {
+		if r.PC != 0 {
+			frames := runtime.CallersFrames([]uintptr{r.PC})
+			frame, _ := frames.Next()
+			e.callerInfo = &CallerInfo{
+				File:     frame.File,
+				Line:     frame.Line,
+				Function: frame.Function,
+			}
+		} else {
+			e.noCaller = true
+		}
+	}
+
+	e.write()
+	return nil
+}
+
+// WithAttrs implements slog.Handler. Outside of any group, the attributes
+// are bound to a child logger, so they are encoded only once. Inside a group,
+// they are kept by the handler and nested in the group of each record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make([]Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	if len(h.groups) == 0 {
+		return &SlogHandler{
+			logger: h.logger.WithFields(fields...),
+		}
+	}
+
+	groups := slices.Clone(h.groups)
+	last := &groups[len(groups)-1]
+	last.fields = append(slices.Clip(last.fields), fields...)
+	return &SlogHandler{
+		logger: h.logger,
+		groups: groups,
+	}
+}
+
+// WithGroup implements slog.Handler. The attributes added afterwards are
+// nested in a Dict named after the group.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{
+		logger: h.logger,
+		groups: append(slices.Clip(h.groups), slogGroup{name: name}),
+	}
+}
+
+// groupFields nests the fields of a record in the groups of the handler,
+// innermost first. Groups left without fields are omitted, as slog requires.
+func (h *SlogHandler) groupFields(fields []Field) []Field {
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		group := h.groups[i]
+		if len(group.fields) == 0 && len(fields) == 0 {
+			continue
+		}
+		members := make([]Field, 0, len(group.fields)+len(fields))
+		members = append(members, group.fields...)
+		members = append(members, fields...)
+		fields = []Field{Dict(group.name, members...)}
+	}
+	return fields
+}
+
+// LevelFromSlog converts a slog.Level to the closest Level.
+func LevelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// LevelToSlog converts a Level to the closest slog.Level.
+func LevelToSlog(level Level) slog.Level {
+	switch {
+	case level <= TraceLevel:
+		return slog.LevelDebug - 4
+	case level <= DebugLevel:
+		return slog.LevelDebug
+	case level <= InfoLevel:
+		return slog.LevelInfo
+	case level <= WarnLevel:
+		return slog.LevelWarn
+	case level <= ErrorLevel:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+// appendSlogAttr converts a slog.Attr to fields and appends them. Groups
+// become Dicts.
+func appendSlogAttr(fields []Field, attr slog.Attr) []Field {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		// A group with an empty key is inlined
+		if attr.Key == "" {
+			for _, groupAttr := range value.Group() {
+				fields = appendSlogAttr(fields, groupAttr)
+			}
+			return fields
+		}
+		var members []Field
+		for _, groupAttr := range value.Group() {
+			members = appendSlogAttr(members, groupAttr)
+		}
+		if len(members) == 0 {
+			return fields
+		}
+		return append(fields, Dict(attr.Key, members...))
+	}
+
+	// Attributes with an empty key are ignored
+	if attr.Key == "" {
+		return fields
+	}
+	return append(fields, fieldFromSlogValue(attr.Key, value))
+}
+
+// fieldFromSlogValue converts a resolved, non-group slog.Value to a Field.
+func fieldFromSlogValue(key string, value slog.Value) Field {
+	switch value.Kind() {
+	case slog.KindString:
+		return Str(key, value.String())
+	case slog.KindInt64:
+		return Int64(key, value.Int64())
+	case slog.KindUint64:
+		return Uint64(key, value.Uint64())
+	case slog.KindFloat64:
+		return Float64(key, value.Float64())
+	case slog.KindBool:
+		return Bool(key, value.Bool())
+	case slog.KindDuration:
+		return Duration(key, value.Duration())
+	case slog.KindTime:
+		return Time(key, value.Time())
+	default:
+		switch v := value.Any().(type) {
+		case error:
+			return NamedErr(key, v)
+		case []byte:
+			return Binary(key, v)
+		default:
+			return Any(key, v)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestSlogHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewJSONFormatter()
+	formatter.Options.MessageKey = slog.MessageKey
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter), WithLevel(DebugLevel)))
+
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		// Records without a time are stamped with the current time
+		if strings.HasSuffix(t.Name(), "/zero-time") {
+			t.Skip("SlogHandler stamps records that have no time")
+		}
+		buf.Reset()
+		return NewSlogHandler(logger)
+	}, func(t *testing.T) map[string]any {
+		var out map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+		}
+		return out
+	})
+}
+
+func TestSlogHandlerGroupsNest(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter()), WithCaller(true)))
+	h := NewSlogHandler(logger).WithAttrs([]slog.Attr{slog.String("app", "api")}).
+		WithGroup("request").WithAttrs([]slog.Attr{slog.String("method", "GET")})
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "served", 0)
+	record.AddAttrs(slog.Group("user", slog.Int("id", 7)))
+	if err := h.Handle(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	request, _ := out["request"].(map[string]any)
+	user, _ := request["user"].(map[string]any)
+	if out["app"] != "api" || request["method"] != "GET" || user["id"] != float64(7) {
+		t.Errorf("output = %s", buf.String())
+	}
+	if _, ok := out["caller"]; ok {
+		t.Errorf("record without PC got a caller: %s", buf.String())
+	}
+}
@@ -0,0 +1,100 @@
+package onelog
+
+import (
+	"log/slog"
+	"time"
+)
+
+// slogNameKey is the attribute key for the logger name in forwarded records.
+const slogNameKey = "logger"
+
+// writeSlog forwards the entry to the logger's slog.Handler.
+func (e *Entry) writeSlog() {
+	handler := e.logger.slogHandler
+	ctx := e.Context()
+	level := LevelToSlog(e.level)
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+
+	var pc uintptr
+	if e.callerInfo != nil {
+		pc = e.callerInfo.pc
+	}
+
+	r := slog.NewRecord(e.time, level, e.message, pc)
+	if e.name != "" {
+		r.AddAttrs(slog.String(slogNameKey, e.name))
+	}
+	traceFields, n := e.traceFields(DefaultTraceKeys())
+	for _, field := range traceFields[:n] {
+		r.AddAttrs(slogAttrFromField(field))
+	}
+	for i := range e.fields {
+		r.AddAttrs(slogAttrFromField(e.fields[i]))
+	}
+
+	if err := handler.Handle(ctx, r); err != nil && e.logger.errorHandler != nil {
+		e.logger.errorHandler(err)
+	}
+}
+
+// slogAttrsFromFields converts fields to slog attributes.
+func slogAttrsFromFields(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, len(fields))
+	for i := range fields {
+		attrs[i] = slogAttrFromField(fields[i])
+	}
+	return attrs
+}
+
+// slogAttrFromField converts a field to a slog attribute, using the native
+// slog.Value kind for the field type where there is one.
+func slogAttrFromField(f Field) slog.Attr {
+	if f.IsSensitive {
+		return slog.String(f.Key, DefaultFormatterOptions().RedactedValue)
+	}
+
+	switch f.Type {
+	case BoolType:
+		return slog.Bool(f.Key, f.Integer == 1)
+	case IntType, Int64Type:
+		return slog.Int64(f.Key, f.Integer)
+	case UintType, Uint64Type:
+		return slog.Uint64(f.Key, uint64(f.Integer))
+	case Float32Type, Float64Type:
+		return slog.Float64(f.Key, f.Float)
+	case StringType:
+		return slog.String(f.Key, f.String)
+	case TimeType:
+		if t, ok := f.Interface.(time.Time); ok {
+			return slog.Time(f.Key, t)
+		}
+	case DurationType:
+		if d, ok := f.Interface.(time.Duration); ok {
+			return slog.Duration(f.Key, d)
+		}
+	case ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return slog.Any(f.Key, err)
+		}
+		return slog.String(f.Key, f.String)
+	case BinaryType, ObjectType, ArrayType, StackType:
+		return slog.Any(f.Key, f.Interface)
+	case LazyType:
+		if lazy, ok := f.Interface.(*lazyField); ok {
+			return slog.Any(f.Key, lazy)
+		}
+	case StringerType, HexType, IPAddrType, URLType, UUIDType:
+		return slog.String(f.Key, textValue(f))
+	case ByteSizeType:
+		return slog.Int64(f.Key, f.Integer)
+	case PercentType:
+		return slog.Float64(f.Key, f.Float)
+	case NamespaceType:
+		// Namespaces are folded into Dicts before entries are forwarded;
+		// a stray marker becomes an empty Attr, which handlers ignore
+		return slog.Attr{}
+	}
+	return slog.Any(f.Key, nil)
+}
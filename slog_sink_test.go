@@ -0,0 +1,93 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// recordingHandler is a slog.Handler that keeps the records it handles.
+type recordingHandler struct {
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+// attrs returns the attributes of the record, resolved, by key.
+func (h *recordingHandler) attrs(t *testing.T) map[string]slog.Value {
+	t.Helper()
+	if len(h.records) != 1 {
+		t.Fatalf("handled %d records, want 1", len(h.records))
+	}
+	attrs := make(map[string]slog.Value)
+	h.records[0].Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.Resolve()
+		return true
+	})
+	return attrs
+}
+
+func TestSlogSinkUsesNativeKinds(t *testing.T) {
+	handler := &recordingHandler{}
+	logger := New(NewConfig(WithSlogHandler(handler)))
+	boom := errors.New("boom")
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	logger.Info("kinds",
+		Duration("elapsed", 2*time.Second),
+		Time("at", at),
+		Err(boom),
+		Binary("data", []byte{1, 2}),
+		Dict("http", Str("method", "GET"), Int("status", 200)),
+	)
+	attrs := handler.attrs(t)
+
+	if v := attrs["elapsed"]; v.Kind() != slog.KindDuration || v.Duration() != 2*time.Second {
+		t.Errorf("elapsed = %v (%v), want a %v", v, v.Kind(), slog.KindDuration)
+	}
+	if v := attrs["at"]; v.Kind() != slog.KindTime || !v.Time().Equal(at) {
+		t.Errorf("at = %v (%v), want a %v", v, v.Kind(), slog.KindTime)
+	}
+	if err, ok := attrs["error"].Any().(error); !ok || err != boom {
+		t.Errorf("error = %#v, want the error value", attrs["error"].Any())
+	}
+	if data, ok := attrs["data"].Any().([]byte); !ok || !bytes.Equal(data, []byte{1, 2}) {
+		t.Errorf("data = %#v, want []byte", attrs["data"].Any())
+	}
+
+	group := attrs["http"]
+	if group.Kind() != slog.KindGroup {
+		t.Fatalf("http = %v (%v), want a %v", group, group.Kind(), slog.KindGroup)
+	}
+	members := group.Group()
+	if len(members) != 2 || members[0].Key != "method" || members[0].Value.String() != "GET" ||
+		members[1].Key != "status" || members[1].Value.Int64() != 200 {
+		t.Errorf("http members = %v", members)
+	}
+}
+
+func TestSlogSinkForwardsLevelAndMessage(t *testing.T) {
+	handler := &recordingHandler{}
+	logger := New(NewConfig(WithSlogHandler(handler))).Named("api")
+	logger.Warn("slow")
+
+	r := handler.records[0]
+	if r.Level != slog.LevelWarn || r.Message != "slow" {
+		t.Errorf("record = %v %q, want %v %q", r.Level, r.Message, slog.LevelWarn, "slow")
+	}
+	if v := handler.attrs(t)[slogNameKey]; v.String() != "api" {
+		t.Errorf("%s = %v, want api", slogNameKey, v)
+	}
+}
@@ -0,0 +1,128 @@
+package onelog
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// stacktraceKey is the key of the field holding an entry's stack trace.
+const stacktraceKey = "stacktrace"
+
+// maxStackDepth is the maximum number of frames captured in a stack trace.
+const maxStackDepth = 64
+
+// Frame is a single call in a stack trace.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// Stacktrace is a stack trace, innermost call first.
+type Stacktrace []Frame
+
+// String returns the stack trace in the format used by Go panics.
+func (s Stacktrace) String() string {
+	var b strings.Builder
+	for i, frame := range s {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+	}
+	return b.String()
+}
+
+// onelogPrefix is the function name prefix of frames in this package.
+var onelogPrefix = reflect.TypeOf(Frame{}).PkgPath() + "."
+
+// walkCallers calls fn for each frame of the current goroutine's stack,
+// starting at the first frame outside this package after skipping skip more
+// frames, until fn returns false or the stack ends.
+func walkCallers(skip int, fn func(runtime.Frame) bool) {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	inPackage := true
+	for {
+		frame, more := frames.Next()
+		if inPackage && strings.HasPrefix(frame.Function, onelogPrefix) {
+			if !more {
+				return
+			}
+			continue
+		}
+		inPackage = false
+		if skip > 0 {
+			skip--
+		} else if !fn(frame) {
+			return
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// captureStacktrace captures the stack above the onelog call site, after
+// skipping skip more frames.
+func captureStacktrace(skip int) Stacktrace {
+	stack := make(Stacktrace, 0, 16)
+	walkCallers(skip, func(frame runtime.Frame) bool {
+		stack = append(stack, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		return true
+	})
+	return stack
+}
+
+// stackField creates a Field holding a stack trace.
+func stackField(key string, stack Stacktrace) Field {
+	return Field{
+		Key:       key,
+		Type:      StackType,
+		Interface: stack,
+	}
+}
+
+// hasStack returns whether the entry already has a stack trace field.
+func (e *Entry) hasStack() bool {
+	for i := range e.fields {
+		if e.fields[i].Type == StackType {
+			return true
+		}
+	}
+	return false
+}
+
+// isStackBlock returns whether a line-oriented formatter writes the field as
+// a block below the line. Redacted stack traces stay inline.
+func isStackBlock(field Field) bool {
+	return field.Type == StackType && !field.IsSensitive
+}
+
+// writeStackBlock writes a stack trace as an indented block of lines, with
+// each frame's function followed by its location.
+func writeStackBlock(buf *bytes.Buffer, key string, stack Stacktrace) {
+	buf.WriteString("\n\t")
+	buf.WriteString(key)
+	buf.WriteByte(':')
+	for _, frame := range stack {
+		buf.WriteString("\n\t\t")
+		buf.WriteString(frame.Function)
+		buf.WriteString("\n\t\t\t")
+		buf.WriteString(frame.File)
+		buf.WriteByte(':')
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(frame.Line), 10))
+	}
+}
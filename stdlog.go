@@ -0,0 +1,131 @@
+package onelog
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// stdLogFileFlags are the standard log flags that record the call site.
+const stdLogFileFlags = log.Lshortfile | log.Llongfile
+
+// RedirectStdLog redirects the output of the standard library's global
+// logger to the given logger at the given level, and returns a function that
+// restores the previous output, flags and prefix.
+//
+// The std logger's prefix and date/time flags are cleared, since the logger
+// adds its own timestamp. If the std logger records file and line, they are
+// parsed into the entry's caller info; if the logger reports callers and the
+// std logger does not, file recording is turned on so the call site is accurate.
+func RedirectStdLog(logger *Logger, level Level) func() {
+	flags := log.Flags()
+	prefix := log.Prefix()
+	output := log.Writer()
+
+	newFlags := flags & stdLogFileFlags
+	if logger.enableCaller && newFlags == 0 {
+		newFlags = log.Llongfile
+	}
+
+	log.SetFlags(newFlags)
+	log.SetPrefix("")
+	log.SetOutput(&stdLogWriter{
+		logger: logger,
+		level:  level,
+		flags:  newFlags,
+	})
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(output)
+	}
+}
+
+// NewStdLog returns a *log.Logger that writes to the given logger at the
+// given level.
+func NewStdLog(logger *Logger, level Level) *log.Logger {
+	flags := 0
+	if logger.enableCaller {
+		flags = log.Llongfile
+	}
+	return log.New(&stdLogWriter{
+		logger: logger,
+		level:  level,
+		flags:  flags,
+	}, "", flags)
+}
+
+// stdLogWriter is an io.Writer that turns standard log output into entries.
+type stdLogWriter struct {
+	logger *Logger
+	level  Level
+	// flags are the flags the std logger writes with.
+	flags int
+}
+
+// Write implements io.Writer. Each non-empty line becomes a separate entry;
+// the std logger writes the call site only once, before the first line.
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	if !w.logger.enabled(w.level) {
+		return len(p), nil
+	}
+
+	msg := string(p)
+	var callerInfo *CallerInfo
+	if w.flags&stdLogFileFlags != 0 {
+		if parsed, rest, ok := parseStdLogCaller(msg); ok {
+			msg = rest
+			if w.logger.enableCaller {
+				callerInfo = parsed
+			}
+		}
+	}
+
+	for len(msg) > 0 {
+		line := msg
+		if i := strings.IndexByte(msg, '\n'); i >= 0 {
+			line = msg[:i]
+			msg = msg[i+1:]
+		} else {
+			msg = ""
+		}
+		if line == "" {
+			continue
+		}
+
+		e := w.logger.newEntry()
+		if callerInfo != nil {
+			info := *callerInfo
+			e.callerInfo = &info
+		}
+		e.level = w.level
+		e.message = line
+		e.write()
+	}
+
+	return len(p), nil
+}
+
+// parseStdLogCaller splits a "file.go:123: message" line into caller info
+// and message.
+func parseStdLogCaller(line string) (*CallerInfo, string, bool) {
+	end := strings.Index(line, ": ")
+	if end < 0 {
+		return nil, line, false
+	}
+	location := line[:end]
+	colon := strings.LastIndexByte(location, ':')
+	if colon <= 0 {
+		return nil, line, false
+	}
+	lineNumber, err := strconv.Atoi(location[colon+1:])
+	if err != nil {
+		return nil, line, false
+	}
+
+	return &CallerInfo{
+		File: location[:colon],
+		Line: lineNumber,
+	}, line[end+2:], true
+}
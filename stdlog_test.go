@@ -0,0 +1,104 @@
+package onelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+)
+
+// decodeLines decodes the JSON entries written to buf, one per line.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON %s: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	output, prefix, flags := log.Writer(), log.Prefix(), log.Flags()
+	defer func() {
+		log.SetOutput(output)
+		log.SetPrefix(prefix)
+		log.SetFlags(flags)
+	}()
+	var buf, previous bytes.Buffer
+	log.SetOutput(&previous)
+	log.SetPrefix("app: ")
+	log.SetFlags(log.LstdFlags)
+
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+	restore := RedirectStdLog(logger, WarnLevel)
+	log.Printf("disk %d%% full", 90)
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 1 || entries[0]["level"] != "WARN" || entries[0]["message"] != "disk 90% full" {
+		t.Errorf("entries = %v", entries)
+	}
+
+	restore()
+	if log.Writer() != &previous || log.Prefix() != "app: " || log.Flags() != log.LstdFlags {
+		t.Errorf("restore left writer %v, prefix %q, flags %d", log.Writer(), log.Prefix(), log.Flags())
+	}
+	log.Print("after restore")
+	if !strings.Contains(previous.String(), "app: ") || !strings.Contains(previous.String(), "after restore") {
+		t.Errorf("previous output = %q", previous.String())
+	}
+}
+
+func TestNewStdLogWritesOneEntryPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(NewJSONFormatter())))
+	NewStdLog(logger, ErrorLevel).Print("first\nsecond\n\nthird")
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("wrote %d entries, want 3: %s", len(entries), buf.String())
+	}
+	for i, want := range []string{"first", "second", "third"} {
+		if entries[i]["message"] != want || entries[i]["level"] != "ERROR" {
+			t.Errorf("entry %d = %v, want ERROR %q", i, entries[i], want)
+		}
+	}
+}
+
+func TestStdLogCallerIsParsed(t *testing.T) {
+	for _, flag := range []int{log.Lshortfile, log.Llongfile} {
+		var buf bytes.Buffer
+		formatter := NewJSONFormatter()
+		formatter.Options.CallerEncoder = FullCallerEncoder
+		logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter), WithCaller(true)))
+		std := log.New(&stdLogWriter{logger: logger, level: InfoLevel, flags: flag}, "", flag)
+		std.Print("hello")
+
+		entries := decodeLines(t, &buf)
+		caller, _ := entries[0]["caller"].(string)
+		file, line, _ := strings.Cut(caller, ":")
+		if entries[0]["message"] != "hello" || line == "" || line == "0" {
+			t.Errorf("flag %d: entry = %v", flag, entries[0])
+		}
+		if flag == log.Lshortfile && file != "stdlog_test.go" {
+			t.Errorf("Lshortfile caller = %q", caller)
+		}
+		if flag == log.Llongfile && (!strings.HasSuffix(file, "/stdlog_test.go") || !strings.HasPrefix(file, "/")) {
+			t.Errorf("Llongfile caller = %q", caller)
+		}
+	}
+}
+
+func TestParseStdLogCaller(t *testing.T) {
+	info, msg, ok := parseStdLogCaller("/src/app/main.go:42: started: ok")
+	if !ok || info.File != "/src/app/main.go" || info.Line != 42 || msg != "started: ok" {
+		t.Errorf("parseStdLogCaller = %+v, %q, %v", info, msg, ok)
+	}
+	if _, msg, ok := parseStdLogCaller("no caller here"); ok || msg != "no caller here" {
+		t.Errorf("parseStdLogCaller without caller = %q, %v", msg, ok)
+	}
+}
@@ -0,0 +1,139 @@
+package onelog
+
+import (
+	"context"
+)
+
+// SpanContextProvider is implemented by tracing spans, so entries can be
+// correlated with traces without depending on a tracing library.
+type SpanContextProvider interface {
+	// SpanContext returns the span's trace ID, span ID and sampling decision.
+	SpanContext() (traceID, spanID string, sampled bool)
+}
+
+// spanContextKey is the context key for the span stored by ContextWithSpan.
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx that carries the given span.
+func ContextWithSpan(ctx context.Context, span SpanContextProvider) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span stored in ctx by ContextWithSpan, or ctx
+// itself if it implements SpanContextProvider.
+func SpanFromContext(ctx context.Context) (SpanContextProvider, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if span, ok := ctx.Value(spanContextKey{}).(SpanContextProvider); ok && span != nil {
+		return span, true
+	}
+	if span, ok := ctx.(SpanContextProvider); ok {
+		return span, true
+	}
+	return nil, false
+}
+
+// TraceKeys controls how formatters name and encode trace correlation fields.
+// The keys are written as given, without the FieldNameConverter, and an
+// empty key omits the corresponding field.
+type TraceKeys struct {
+	// TraceID is the key for the trace ID.
+	TraceID string
+	// SpanID is the key for the span ID.
+	SpanID string
+	// TraceFlags is the key for the trace flags.
+	TraceFlags string
+	// TraceIDPrefix is prepended to the trace ID.
+	TraceIDPrefix string
+	// SampledAsBool encodes the trace flags as a boolean sampling decision
+	// instead of W3C hex flags ("01" or "00").
+	SampledAsBool bool
+}
+
+// DefaultTraceKeys returns the default trace keys: trace_id, span_id and trace_flags.
+func DefaultTraceKeys() TraceKeys {
+	return TraceKeys{
+		TraceID:    "trace_id",
+		SpanID:     "span_id",
+		TraceFlags: "trace_flags",
+	}
+}
+
+// GCPTraceKeys returns the trace keys recognised by Google Cloud Logging
+// for the given project.
+func GCPTraceKeys(projectID string) TraceKeys {
+	return TraceKeys{
+		TraceID:       "logging.googleapis.com/trace",
+		SpanID:        "logging.googleapis.com/spanId",
+		TraceFlags:    "logging.googleapis.com/trace_sampled",
+		TraceIDPrefix: "projects/" + projectID + "/traces/",
+		SampledAsBool: true,
+	}
+}
+
+// ECSTraceKeys returns the trace keys defined by the Elastic Common Schema.
+// ECS has no field for trace flags, so they are omitted.
+func ECSTraceKeys() TraceKeys {
+	return TraceKeys{
+		TraceID: "trace.id",
+		SpanID:  "span.id",
+	}
+}
+
+// spanInfo holds the span correlation data of an entry.
+type spanInfo struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+// captureSpan records the span carried by the entry's context, if any.
+func (e *Entry) captureSpan() {
+	span, ok := SpanFromContext(e.ctx)
+	if !ok {
+		return
+	}
+	traceID, spanID, sampled := span.SpanContext()
+	if traceID == "" && spanID == "" {
+		return
+	}
+	e.span = spanInfo{
+		traceID: traceID,
+		spanID:  spanID,
+		sampled: sampled,
+	}
+}
+
+// traceFields returns the entry's trace correlation fields named by keys.
+func (e *Entry) traceFields(keys TraceKeys) ([3]Field, int) {
+	var fields [3]Field
+	n := 0
+	if e.span.traceID == "" && e.span.spanID == "" {
+		return fields, n
+	}
+
+	if keys.TraceID != "" && e.span.traceID != "" {
+		fields[n] = Str(keys.TraceID, keys.TraceIDPrefix+e.span.traceID)
+		n++
+	}
+	if keys.SpanID != "" && e.span.spanID != "" {
+		fields[n] = Str(keys.SpanID, e.span.spanID)
+		n++
+	}
+	if keys.TraceFlags != "" {
+		switch {
+		case keys.SampledAsBool:
+			fields[n] = Bool(keys.TraceFlags, e.span.sampled)
+		case e.span.sampled:
+			fields[n] = Str(keys.TraceFlags, "01")
+		default:
+			fields[n] = Str(keys.TraceFlags, "00")
+		}
+		n++
+	}
+	return fields, n
+}
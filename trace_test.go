@@ -0,0 +1,66 @@
+package onelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// upperKeys is a FieldNameConverter that would mangle trace keys.
+func upperKeys(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func TestGCPTraceKeysAreWrittenVerbatim(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewJSONFormatter()
+	formatter.Options.TraceKeys = GCPTraceKeys("my-project")
+	formatter.Options.FieldNameConverter = upperKeys
+	logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter)))
+
+	ctx := ContextWithSpan(context.Background(), testSpan{})
+	logger.WithContext(ctx).Str("user.id", "ann").Info("hi")
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"logging.googleapis.com/trace":         "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+		"logging.googleapis.com/spanId":        "00f067aa0ba902b7",
+		"logging.googleapis.com/trace_sampled": true,
+		"USER_ID":                              "ann",
+	}
+	for key, value := range want {
+		if out[key] != value {
+			t.Errorf("%s = %v, want %v in %s", key, out[key], value, buf.String())
+		}
+	}
+}
+
+func TestECSTraceKeysAreWrittenVerbatim(t *testing.T) {
+	for name, formatter := range map[string]optionsFormatter{
+		"json":   NewJSONFormatter(),
+		"logfmt": NewLogfmtFormatter(),
+		"text":   NewTextFormatter(),
+	} {
+		opts := formatter.formatterOptions()
+		opts.TraceKeys = ECSTraceKeys()
+		opts.FieldNameConverter = upperKeys
+		var buf bytes.Buffer
+		logger := New(NewConfig(WithWriter(&buf), WithFormatter(formatter.(Formatter))))
+
+		ctx := ContextWithSpan(context.Background(), testSpan{})
+		logger.WithContext(ctx).Info("hi")
+
+		out := buf.String()
+		if !strings.Contains(out, "trace.id") || !strings.Contains(out, "span.id") {
+			t.Errorf("%s output has no ECS trace keys: %s", name, out)
+		}
+		if strings.Contains(out, "TRACE_ID") || strings.Contains(out, "trace_flags") {
+			t.Errorf("%s output has converted or extra trace keys: %s", name, out)
+		}
+	}
+}
@@ -302,23 +302,39 @@ func encodeBase64(dst *bytes.Buffer, src []byte) {
 	}
 }
 
-// SensitiveKeys contains keys that should be redacted in logs.
+// SensitiveKeys contains the key patterns that are redacted in logs. Keys
+// are matched case-insensitively: a pattern matches a key equal to it, a
+// pattern "*suffix" any key ending with suffix, a pattern "*sub*" any key
+// containing sub, and any other pattern with the metacharacters of path.Match
+// as a glob. A pattern starting with "!" exempts the keys it matches, so that
+// "*_key" can redact ssh_key but not cache_key.
 var SensitiveKeys = []string{
-	"password", "passwd", "secret", "token", "auth",
-	"credential", "credentials", "api_key", "apikey",
-	"access_token", "accesstoken", "refresh_token",
-	"private_key", "privatekey", "authorization", "key",
+	"*password*", "*passwd*", "*secret*", "*token*", "*credential*",
+	"*api_key*", "*apikey*", "*api-key*", "*private_key*", "*privatekey*",
+	"authorization", "auth", "auth_*", "*_auth", "*_auth_*",
+	"*_key", "*-key",
+	"!cache_key", "!idempotency_key", "!partition_key", "!primary_key",
+	"!foreign_key", "!sort_key", "!routing_key", "!sharding_key",
+	"!max_tokens", "!input_tokens", "!output_tokens", "!prompt_tokens",
+	"!completion_tokens", "!total_tokens", "!token_count", "!token_type",
 }
 
-// IsSensitiveKey returns true if the key is sensitive.
+// IsSensitiveKey returns true if the key matches one of SensitiveKeys and
+// none of its exemptions.
 func IsSensitiveKey(key string) bool {
-	lowerKey := strings.ToLower(key)
-	for _, sensitiveKey := range SensitiveKeys {
-		if strings.Contains(lowerKey, sensitiveKey) {
-			return true
+	lowerKey := fastLowerCase(key)
+	sensitive := false
+	for _, pattern := range SensitiveKeys {
+		pattern = strings.ToLower(pattern)
+		if exempt, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchKeyPattern(exempt, lowerKey) {
+				return false
+			}
+		} else if !sensitive {
+			sensitive = matchKeyPattern(pattern, lowerKey)
 		}
 	}
-	return false
+	return sensitive
 }
 
 // fastLowerCase converts ASCII string to lowercase without allocations
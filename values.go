@@ -0,0 +1,169 @@
+package onelog
+
+import (
+	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// Stringer creates a Field whose value is the result of val.String(). The
+// method is only called when an entry carrying the field is written.
+func Stringer(key string, val fmt.Stringer) Field {
+	return Field{
+		Key:       key,
+		Type:      StringerType,
+		Interface: val,
+	}
+}
+
+// Hex creates a Field with a []byte value written as lowercase hex.
+func Hex(key string, val []byte) Field {
+	return Field{
+		Key:       key,
+		Type:      HexType,
+		Interface: val,
+	}
+}
+
+// IPAddr creates a Field with an IP address value.
+func IPAddr(key string, val netip.Addr) Field {
+	return Field{
+		Key:       key,
+		Type:      IPAddrType,
+		Interface: val,
+	}
+}
+
+// URL creates a Field with a URL value.
+func URL(key string, val *url.URL) Field {
+	return Field{
+		Key:       key,
+		Type:      URLType,
+		Interface: val,
+	}
+}
+
+// UUID creates a Field with a UUID value, written in its canonical
+// xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx form.
+func UUID(key string, val [16]byte) Field {
+	return Field{
+		Key:       key,
+		Type:      UUIDType,
+		Interface: val,
+	}
+}
+
+// ByteSize creates a Field with a size in bytes. The JSON and logfmt
+// formatters write it as a number, the text formatter in human-readable form
+// such as "1.5 MiB".
+func ByteSize(key string, bytes int64) Field {
+	return Field{
+		Key:     key,
+		Type:    ByteSizeType,
+		Integer: bytes,
+	}
+}
+
+// Percent creates a Field with a percentage, such as 42.5 for 42.5%. The JSON
+// and logfmt formatters write it as a number, the text formatter with a
+// trailing "%".
+func Percent(key string, val float64) Field {
+	return Field{
+		Key:   key,
+		Type:  PercentType,
+		Float: val,
+	}
+}
+
+// appendTextValue appends the text of a Stringer, Hex, IPAddr, URL or UUID
+// field to dst.
+func appendTextValue(dst []byte, field Field) []byte {
+	switch field.Type {
+	case StringerType:
+		s, _ := field.Interface.(fmt.Stringer)
+		return append(dst, stringerValue(s)...)
+	case HexType:
+		data, _ := field.Interface.([]byte)
+		return appendHex(dst, data)
+	case IPAddrType:
+		addr, _ := field.Interface.(netip.Addr)
+		return addr.AppendTo(dst)
+	case URLType:
+		u, _ := field.Interface.(*url.URL)
+		if u == nil {
+			return append(dst, nilValue...)
+		}
+		return append(dst, u.String()...)
+	case UUIDType:
+		id, _ := field.Interface.([16]byte)
+		return appendUUID(dst, id)
+	}
+	return dst
+}
+
+// textValue returns the text of a Stringer, Hex, IPAddr, URL or UUID field.
+func textValue(field Field) string {
+	return string(appendTextValue(nil, field))
+}
+
+// stringerValue calls s.String(), returning "<nil>" for a nil Stringer or
+// nil pointer and a description of the panic if String panics.
+func stringerValue(s fmt.Stringer) (str string) {
+	if s == nil {
+		return nilValue
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(s); v.Kind() == reflect.Pointer && v.IsNil() {
+				str = nilValue
+				return
+			}
+			str = fmt.Sprintf("<PANIC=%v>", r)
+		}
+	}()
+	return s.String()
+}
+
+// appendUUID appends id in its canonical form.
+func appendUUID(dst []byte, id [16]byte) []byte {
+	dst = appendHex(dst, id[0:4])
+	dst = append(dst, '-')
+	dst = appendHex(dst, id[4:6])
+	dst = append(dst, '-')
+	dst = appendHex(dst, id[6:8])
+	dst = append(dst, '-')
+	dst = appendHex(dst, id[8:10])
+	dst = append(dst, '-')
+	return appendHex(dst, id[10:16])
+}
+
+// appendHex appends data as lowercase hex.
+func appendHex(dst []byte, data []byte) []byte {
+	for _, c := range data {
+		dst = append(dst, hex[c>>4], hex[c&0xF])
+	}
+	return dst
+}
+
+// byteSizeUnits are the binary units of human-readable sizes.
+var byteSizeUnits = [...]string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// appendByteSize appends a size in bytes in human-readable form, with one
+// decimal at most, such as "512 B" or "1.5 MiB".
+func appendByteSize(dst []byte, bytes int64) []byte {
+	size := math.Abs(float64(bytes))
+	unit := 0
+	for size >= 1024 && unit < len(byteSizeUnits)-1 {
+		size /= 1024
+		unit++
+	}
+	if bytes < 0 {
+		size = -size
+	}
+	dst = strconv.AppendFloat(dst, math.Round(size*10)/10, 'f', -1, 64)
+	dst = append(dst, ' ')
+	return append(dst, byteSizeUnits[unit]...)
+}
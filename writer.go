@@ -2,14 +2,31 @@ package onelog
 
 import (
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 )
 
+// syncWriter syncs w if it supports it. The EINVAL and ENOTSUP errors that
+// fsync returns for pipes, terminals and other files that cannot be synced,
+// such as os.Stdout, are ignored.
+func syncWriter(w io.Writer) error {
+	syncer, ok := w.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	err := syncer.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+		return nil
+	}
+	return err
+}
+
 // LogWriter is an interface for log writers.
 type LogWriter interface {
 	io.Writer
@@ -181,6 +198,18 @@ func (w *FileWriter) Close() error {
 	return err
 }
 
+// Sync commits the file's contents to stable storage.
+func (w *FileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	
+	if w.file == nil {
+		return nil
+	}
+	
+	return w.file.Sync()
+}
+
 // rotate rotates the log file.
 func (w *FileWriter) rotate() error {
 	// Close the current file
@@ -356,6 +385,21 @@ func (w *MultiWriter) Close() error {
 	return firstErr
 }
 
+// Sync syncs every writer that supports it.
+func (w *MultiWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	
+	var firstErr error
+	for _, writer := range w.writers {
+		if err := syncWriter(writer); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	
+	return firstErr
+}
+
 // AddWriter adds a writer to the MultiWriter.
 func (w *MultiWriter) AddWriter(writer LogWriter) {
 	w.mu.Lock()